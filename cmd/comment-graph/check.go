@@ -3,29 +3,113 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/kuri-sun/comment-graph/internal/engine"
+	"github.com/kuri-sun/comment-graph/internal/graph"
 )
 
-func runCheck(p printer, dir string) int {
-	root, err := resolveRoot(dir)
+func runCheck(p printer, opts checkFlags) int {
+	root, err := resolveRoot(opts.scan.dir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to resolve working directory: %v\n", err)
 		return 1
 	}
 
-	scanned, scanErrs, err := engine.Scan(root)
+	if opts.fix && (opts.scan.rev != "" || opts.scan.overlay != "" || len(opts.scan.files) > 0 || opts.scan.filesFrom != "") {
+		fmt.Fprintln(os.Stderr, "--fix rewrites the working tree and cannot be combined with --rev, --overlay or --files")
+		return 1
+	}
+	if opts.fix && opts.format != "text" {
+		fmt.Fprintln(os.Stderr, "--fix reports its changes as text and cannot be combined with --format")
+		return 1
+	}
+	if opts.against != "" && opts.format != "text" {
+		fmt.Fprintln(os.Stderr, "--against reports drift as text and cannot be combined with --format or --json")
+		return 1
+	}
+
+	var stored *graph.Graph
+	if opts.against != "" {
+		g, err := engine.ReadGraphFile(resolvePath(root, opts.against))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read graph: %v\n", err)
+			return 1
+		}
+		stored = &g
+	}
+
+	cfg, err := loadConfig(root, opts.scan)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+
+	scanned, scanErrs, err := scanRepo(root, opts.scan)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "scan failed: %v\n", err)
 		p.resultLine(false)
 		return 3
 	}
 
-	report := engine.ValidateGraph(scanned, scanErrs)
-	if len(report.ScanErrors) > 0 || len(report.UndefinedEdges) > 0 || len(report.Cycles) > 0 || len(report.Isolated) > 0 {
-		if code, failed := validateAndReport(p, "Check completed", scanned, report, nil, false); failed {
-			return code
+	if opts.writeBaseline {
+		return writeBaseline(p, root, opts, engine.ValidateGraphWithConfig(scanned, scanErrs, cfg))
+	}
+	if opts.baseline != "" {
+		baseline, err := engine.ReadBaselineFile(resolvePath(root, opts.baseline))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read baseline: %v\n", err)
+			return 1
+		}
+		cfg.Baseline = &baseline
+	}
+
+	report := engine.ValidateGraphWithConfig(scanned, scanErrs, cfg)
+	// fixFailed fails the check when a redundant dependency could not be
+	// removed; the rescan below still reports it as a warning.
+	fixFailed := false
+	if opts.fix {
+		if redundant := engine.RedundantDeps(report.Findings); len(redundant) > 0 {
+			removed, err := engine.RemoveDeps(root, scanned, redundant)
+			for _, e := range removed {
+				p.okLine(fmt.Sprintf("removed %q from the @cgraph-deps of %q", e.From, e.To))
+			}
+			if err != nil {
+				p.errLine(err.Error())
+				fixFailed = true
+			}
+			if scanned, scanErrs, err = scanRepo(root, opts.scan); err != nil {
+				fmt.Fprintf(os.Stderr, "scan failed: %v\n", err)
+				p.resultLine(false)
+				return 3
+			}
+			report = engine.ValidateGraphWithConfig(scanned, scanErrs, cfg)
+		}
+	}
+	if opts.format != "text" {
+		out, err := renderCheck(opts.format, scanned, report, cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to render %s: %v\n", opts.format, err)
+			return 1
 		}
+		fmt.Print(string(out))
+		if len(out) > 0 && out[len(out)-1] != '\n' {
+			fmt.Println()
+		}
+		code, _ := validationStatus(scanned, report, nil, false)
+		return code
+	}
+	reportWarnings(p, report.Findings)
+	reportBaseline(p, report)
+	if code, failed := validationStatus(scanned, report, stored, stored != nil); failed {
+		validateAndReport(p, "Check completed", scanned, report, stored, stored != nil)
+		return code
+	}
+	if fixFailed {
+		fmt.Println()
+		p.section("Check complete")
+		p.resultLine(false)
+		return 1
 	}
 
 	fmt.Println()
@@ -36,3 +120,43 @@ func runCheck(p printer, dir string) int {
 	p.infof("total nodes: %d", len(scanned.Nodes))
 	return 0
 }
+
+func writeBaseline(p printer, root string, opts checkFlags, report engine.CheckReport) int {
+	path := opts.baselineOut
+	if path == "" {
+		path = opts.baseline
+	}
+	if path == "" {
+		path = engine.DefaultBaselineFile
+	}
+	if err := engine.WriteBaselineFile(resolvePath(root, path), engine.NewBaseline(report.Findings)); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write baseline: %v\n", err)
+		return 1
+	}
+	p.okLine(fmt.Sprintf("baseline written to %s (%d findings)", path, len(report.Findings)))
+	return 0
+}
+
+// reportBaseline summarises findings hidden by the baseline and entries that
+// have been fixed since it was written.
+func reportBaseline(p printer, report engine.CheckReport) {
+	if report.Baselined > 0 {
+		p.infof("%d known findings hidden by baseline", report.Baselined)
+	}
+	for _, e := range report.Fixed {
+		msg := "fixed: " + e.Rule
+		if e.File != "" {
+			msg += " in " + e.File
+		}
+		if len(e.Nodes) > 0 {
+			msg += " (" + strings.Join(e.Nodes, ", ") + ")"
+		}
+		if e.Count > 1 {
+			msg += fmt.Sprintf(" x%d", e.Count)
+		}
+		p.okLine(msg)
+	}
+	if len(report.Fixed) > 0 {
+		p.infof("run `comment-graph check --write-baseline` to drop fixed entries from the baseline")
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kuri-sun/comment-graph/internal/engine"
+)
+
+// runExplain prints the documentation of an error code, or lists every code
+// when none is given.
+func runExplain(args []string) int {
+	if len(args) == 0 {
+		for _, c := range engine.Codes {
+			fmt.Printf("%s  %-18s %s\n", c.ID, c.Rule, c.Title)
+		}
+		return 0
+	}
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "usage: comment-graph explain [code]")
+		return 1
+	}
+	c, ok := engine.LookupCode(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown code %q (run `comment-graph explain` to list codes)\n", args[0])
+		return 1
+	}
+	fmt.Printf("%s: %s [%s]\n\n", c.ID, c.Title, c.Rule)
+	fmt.Println(c.Description)
+	fmt.Println()
+	fmt.Println("Example:")
+	fmt.Println(indent(c.Example))
+	fmt.Println()
+	fmt.Println("Fix:")
+	fmt.Println(indent(c.Fix))
+	return 0
+}
+
+func indent(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = "  " + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kuri-sun/comment-graph/internal/engine"
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// exportFormats lists the values accepted by export --format.
+var exportFormats = []string{"dot", "mermaid", "plantuml", "d2", "graphml", "gexf", "cytoscape"}
+
+// runExport scans the repository and prints the graph in opts.format. It
+// describes the graph rather than validating it, so findings do not change
+// the exit code.
+func runExport(opts exportFlags) int {
+	root, err := resolveRoot(opts.scan.dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to resolve working directory: %v\n", err)
+		return 1
+	}
+	cfg, err := loadConfig(root, opts.scan)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	scanned, scanErrs, err := scanRepo(root, opts.scan)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan failed: %v\n", err)
+		return 1
+	}
+	report := engine.ValidateGraphWithConfig(scanned, scanErrs, cfg)
+	out, err := renderExport(opts, scanned, report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to render %s: %v\n", opts.format, err)
+		return 1
+	}
+	fmt.Print(out)
+	return 0
+}
+
+func renderExport(opts exportFlags, g graph.Graph, report engine.CheckReport) (string, error) {
+	diagram := engine.DiagramOptions{
+		GroupBy:   opts.group,
+		Labels:    !opts.noLabels,
+		EdgeTypes: opts.edgeTypes,
+		Report:    &report,
+		LinkBase:  opts.linkBase,
+	}
+	switch opts.format {
+	case "dot":
+		return engine.RenderDOT(g, diagram), nil
+	case "mermaid":
+		return engine.RenderMermaidWithOptions(g, diagram), nil
+	case "plantuml":
+		return engine.RenderPlantUML(g, diagram), nil
+	case "d2":
+		return engine.RenderD2(g, diagram), nil
+	case "graphml":
+		data, err := engine.RenderGraphML(g, diagram)
+		return string(data) + "\n", err
+	case "gexf":
+		data, err := engine.RenderGEXF(g, diagram)
+		return string(data) + "\n", err
+	case "cytoscape":
+		data, err := engine.RenderCytoscape(g, diagram)
+		return string(data) + "\n", err
+	default:
+		return "", fmt.Errorf("unknown format %q", opts.format)
+	}
+}
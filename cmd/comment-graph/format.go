@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kuri-sun/comment-graph/internal/engine"
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// checkFormats lists the values accepted by check --format; text is the
+// colored human-readable report and json is what check --json prints.
+var checkFormats = []string{"text", "json", "sarif", "junit", "tap", "github", "gitlab"}
+
+// renderCheck renders report in one of the machine-readable check formats.
+func renderCheck(format string, g graph.Graph, report engine.CheckReport, cfg engine.Config) ([]byte, error) {
+	switch format {
+	case "json":
+		return engine.RenderFindingsJSON(report)
+	case "sarif":
+		return engine.RenderSARIF(report, version)
+	case "junit":
+		return engine.RenderJUnit(g, report, cfg)
+	case "tap":
+		return []byte(engine.RenderTAP(g, report, cfg)), nil
+	case "github":
+		return []byte(engine.RenderGitHubAnnotations(report)), nil
+	case "gitlab":
+		return engine.RenderGitLabCodeQuality(report)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
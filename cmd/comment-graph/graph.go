@@ -7,24 +7,30 @@ import (
 	"github.com/kuri-sun/comment-graph/internal/engine"
 )
 
-func runGraph(p printer, dir string, allowErrors bool) int {
-	root, err := resolveRoot(dir)
+func runGraph(p printer, opts graphFlags) int {
+	root, err := resolveRoot(opts.scan.dir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to resolve working directory: %v\n", err)
 		return 1
 	}
 
-	graph, errs, err := engine.Scan(root)
+	cfg, err := loadConfig(root, opts.scan)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+
+	graph, errs, err := scanRepo(root, opts.scan)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "scan failed: %v\n", err)
 		p.resultLine(false)
 		return 1
 	}
 
-	report := engine.ValidateGraph(graph, errs)
+	report := engine.ValidateGraphWithConfig(graph, errs, cfg)
 	code, failed := validationStatus(graph, report, nil, false)
 	exitCode := code
-	if failed && allowErrors {
+	if failed && opts.allowErrors {
 		exitCode = 0
 	}
 
@@ -34,7 +40,7 @@ func runGraph(p printer, dir string, allowErrors bool) int {
 		return 1
 	}
 	fmt.Println(string(payload))
-	if failed && !allowErrors {
+	if failed && !opts.allowErrors {
 		return code
 	}
 	return exitCode
@@ -4,6 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kuri-sun/comment-graph/internal/engine"
 )
 
 var version = "dev"
@@ -19,19 +24,37 @@ func main() {
 	cmd := os.Args[1]
 	switch cmd {
 	case "graph":
-		dir, allowErrors, err := parseGraphFlags(os.Args[2:])
+		opts, err := parseGraphFlags(os.Args[2:])
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
-		os.Exit(runGraph(p, dir, allowErrors))
+		os.Exit(runGraph(p, opts))
 	case "check":
-		dir, err := parseDirFlag(os.Args[2:], "check")
+		opts, err := parseCheckFlags(os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(runCheck(p, opts))
+	case "export":
+		opts, err := parseExportFlags(os.Args[2:])
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
-		os.Exit(runCheck(p, dir))
+		os.Exit(runExport(opts))
+	case "report":
+		opts, err := parseReportFlags(os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(runReport(p, opts))
+	case "explain":
+		os.Exit(runExplain(os.Args[2:]))
+	case "schema":
+		os.Exit(runSchema(os.Args[2:]))
 	case "version", "--version", "-v":
 		fmt.Println(version)
 		return
@@ -59,45 +82,310 @@ func currentRoot() (string, error) {
 	return filepath.Abs(root)
 }
 
-func parseGraphFlags(args []string) (string, bool, error) {
-	dir := ""
-	allowErrors := false
+// scanFlags select what a command scans and how the result is validated;
+// they are shared by graph, check, export and report.
+type scanFlags struct {
+	dir       string
+	rev       string
+	ignore    []string
+	overlay   string
+	files     []string
+	filesFrom string
+	base      string
+	follow    bool
+	maxSize   int64
+	maxLine   int
+	config    string
+}
+
+type graphFlags struct {
+	scan        scanFlags
+	allowErrors bool
+}
+
+type exportFlags struct {
+	scan      scanFlags
+	format    string
+	group     string
+	noLabels  bool
+	edgeTypes bool
+	linkBase  string
+}
+
+type reportFlags struct {
+	scan     scanFlags
+	html     string
+	title    string
+	linkBase string
+}
+
+type checkFlags struct {
+	scan          scanFlags
+	baseline      string
+	writeBaseline bool
+	baselineOut   string
+	fix           bool
+	against       string
+	format        string
+}
+
+func parseGraphFlags(args []string) (graphFlags, error) {
+	var opts graphFlags
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--allow-errors":
+			if opts.allowErrors {
+				return graphFlags{}, fmt.Errorf("duplicate --allow-errors flag")
+			}
+			opts.allowErrors = true
+		default:
+			next, ok, err := parseScanFlag(args, i, &opts.scan)
+			if err != nil {
+				return graphFlags{}, err
+			}
+			if !ok {
+				return graphFlags{}, fmt.Errorf("unknown flag for graph: %s", args[i])
+			}
+			i = next
+		}
+	}
+	return opts, nil
+}
+
+func parseCheckFlags(args []string) (checkFlags, error) {
+	opts := checkFlags{format: "text"}
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
-		case "--dir":
-			if i+1 >= len(args) {
-				return "", false, fmt.Errorf("missing value for --dir")
+		case "--format":
+			val, err := flagValue(args, i)
+			if err != nil {
+				return checkFlags{}, err
+			}
+			if !slices.Contains(checkFormats, val) {
+				return checkFlags{}, fmt.Errorf("invalid --format %q (use %s)", val, strings.Join(checkFormats, ", "))
 			}
-			dir = args[i+1]
+			opts.format = val
 			i++
-		case "--allow-errors":
-			if allowErrors {
-				return "", false, fmt.Errorf("duplicate --allow-errors flag")
+		case "--baseline":
+			val, err := flagValue(args, i)
+			if err != nil {
+				return checkFlags{}, err
+			}
+			opts.baseline = val
+			i++
+		case "--json":
+			opts.format = "json"
+		case "--fix":
+			opts.fix = true
+		case "--against":
+			val, err := flagValue(args, i)
+			if err != nil {
+				return checkFlags{}, err
+			}
+			opts.against = val
+			i++
+		case "--write-baseline":
+			opts.writeBaseline = true
+			// the path is optional
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+				opts.baselineOut = args[i+1]
+				i++
+			}
+		default:
+			next, ok, err := parseScanFlag(args, i, &opts.scan)
+			if err != nil {
+				return checkFlags{}, err
+			}
+			if !ok {
+				return checkFlags{}, fmt.Errorf("unknown flag for check: %s", args[i])
+			}
+			i = next
+		}
+	}
+	return opts, nil
+}
+
+func parseExportFlags(args []string) (exportFlags, error) {
+	opts := exportFlags{group: engine.GroupByDir}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--format":
+			val, err := flagValue(args, i)
+			if err != nil {
+				return exportFlags{}, err
+			}
+			if !slices.Contains(exportFormats, val) {
+				return exportFlags{}, fmt.Errorf("invalid --format %q (use %s)", val, strings.Join(exportFormats, ", "))
+			}
+			opts.format = val
+			i++
+		case "--group":
+			val, err := flagValue(args, i)
+			if err != nil {
+				return exportFlags{}, err
+			}
+			switch val {
+			case engine.GroupByFile, engine.GroupByDir:
+				opts.group = val
+			case "none":
+				opts.group = ""
+			default:
+				return exportFlags{}, fmt.Errorf("invalid --group %q (use file, dir, or none)", val)
 			}
-			allowErrors = true
+			i++
+		case "--no-labels":
+			opts.noLabels = true
+		case "--edge-types":
+			opts.edgeTypes = true
+		case "--link-base":
+			val, err := flagValue(args, i)
+			if err != nil {
+				return exportFlags{}, err
+			}
+			opts.linkBase = val
+			i++
 		default:
-			return "", false, fmt.Errorf("unknown flag for graph: %s", args[i])
+			next, ok, err := parseScanFlag(args, i, &opts.scan)
+			if err != nil {
+				return exportFlags{}, err
+			}
+			if !ok {
+				return exportFlags{}, fmt.Errorf("unknown flag for export: %s", args[i])
+			}
+			i = next
 		}
 	}
-	return dir, allowErrors, nil
+	if opts.format == "" {
+		return exportFlags{}, fmt.Errorf("export needs --format (%s)", strings.Join(exportFormats, ", "))
+	}
+	return opts, nil
 }
 
-func parseDirFlag(args []string, cmd string) (string, error) {
-	dir := ""
+func parseReportFlags(args []string) (reportFlags, error) {
+	var opts reportFlags
 	for i := 0; i < len(args); i++ {
-		arg := args[i]
-		switch arg {
-		case "--dir":
-			if i+1 >= len(args) {
-				return "", fmt.Errorf("missing value for --dir")
+		switch args[i] {
+		case "--html", "--title", "--link-base":
+			val, err := flagValue(args, i)
+			if err != nil {
+				return reportFlags{}, err
+			}
+			switch args[i] {
+			case "--html":
+				opts.html = val
+			case "--title":
+				opts.title = val
+			default:
+				opts.linkBase = val
 			}
-			dir = args[i+1]
 			i++
 		default:
-			return "", fmt.Errorf("unknown flag for %s: %s", cmd, arg)
+			next, ok, err := parseScanFlag(args, i, &opts.scan)
+			if err != nil {
+				return reportFlags{}, err
+			}
+			if !ok {
+				return reportFlags{}, fmt.Errorf("unknown flag for report: %s", args[i])
+			}
+			i = next
+		}
+	}
+	if opts.html == "" {
+		return reportFlags{}, fmt.Errorf("report needs --html <file>")
+	}
+	return opts, nil
+}
+
+// parseScanFlag consumes args[i] if it is a scan flag, returning the index of
+// the last argument it used.
+func parseScanFlag(args []string, i int, f *scanFlags) (int, bool, error) {
+	switch args[i] {
+	case "--dir":
+		val, err := flagValue(args, i)
+		if err != nil {
+			return i, true, err
 		}
+		f.dir = val
+		return i + 1, true, nil
+	case "--rev":
+		val, err := flagValue(args, i)
+		if err != nil {
+			return i, true, err
+		}
+		f.rev = val
+		return i + 1, true, nil
+	case "--ignore":
+		val, err := flagValue(args, i)
+		if err != nil {
+			return i, true, err
+		}
+		f.ignore = append(f.ignore, val)
+		return i + 1, true, nil
+	case "--overlay":
+		val, err := flagValue(args, i)
+		if err != nil {
+			return i, true, err
+		}
+		f.overlay = val
+		return i + 1, true, nil
+	case "--files":
+		j := i + 1
+		for ; j < len(args) && !strings.HasPrefix(args[j], "--"); j++ {
+			f.files = append(f.files, args[j])
+		}
+		if j == i+1 {
+			return i, true, fmt.Errorf("missing value for --files")
+		}
+		return j - 1, true, nil
+	case "--files-from":
+		val, err := flagValue(args, i)
+		if err != nil {
+			return i, true, err
+		}
+		f.filesFrom = val
+		return i + 1, true, nil
+	case "--max-file-size", "--max-line-length":
+		val, err := flagValue(args, i)
+		if err != nil {
+			return i, true, err
+		}
+		n, err := strconv.Atoi(val)
+		if err != nil || n == 0 || n < -1 {
+			return i, true, fmt.Errorf("invalid value for %s: %q (use a positive size, or -1 for no limit)", args[i], val)
+		}
+		if args[i] == "--max-file-size" {
+			f.maxSize = int64(n)
+		} else {
+			f.maxLine = n
+		}
+		return i + 1, true, nil
+	case "--config":
+		val, err := flagValue(args, i)
+		if err != nil {
+			return i, true, err
+		}
+		f.config = val
+		return i + 1, true, nil
+	case "--follow-symlinks":
+		f.follow = true
+		return i, true, nil
+	case "--base":
+		val, err := flagValue(args, i)
+		if err != nil {
+			return i, true, err
+		}
+		f.base = val
+		return i + 1, true, nil
+	default:
+		return i, false, nil
+	}
+}
+
+func flagValue(args []string, i int) (string, error) {
+	if i+1 >= len(args) {
+		return "", fmt.Errorf("missing value for %s", args[i])
 	}
-	return dir, nil
+	return args[i+1], nil
 }
 
 func printHelp() {
@@ -105,9 +393,49 @@ func printHelp() {
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Println("  comment-graph graph     Scan repository and stream graph+report JSON to stdout (no files written)")
-	fmt.Println("      --dir <path>        Target a different root")
+	fmt.Println("      --dir <path>        Target a different root (relative path flags resolve against it)")
+	fmt.Println("      --rev <ref>         Scan the tree of a git revision instead of the working tree")
+	fmt.Println("      --ignore <glob>     Skip matching files and directories (repeatable)")
+	fmt.Println("      --follow-symlinks   Scan symlinked files and directories (loops are detected and skipped)")
+	fmt.Println("      --max-file-size <n> Skip files larger than n bytes with a warning (default 10 MiB, -1 for no limit)")
+	fmt.Println("      --max-line-length <n>  Skip files with lines longer than n bytes (default 64 KiB, -1 for no limit)")
+	fmt.Println("      --overlay <file|->  Scan unsaved buffers from a {\"path\": \"contents\"} JSON file or stdin")
+	fmt.Println("      --files <paths...>  Re-scan only these files and merge them into the stored graph")
+	fmt.Println("      --files-from <f|->  Like --files, reading one path per line from a file or stdin")
+	fmt.Println("      --base <path>       Stored graph to merge into (default comment-graph.yml)")
+	fmt.Println("      --config <path>     Rule configuration (default .comment-graph.yml in the root)")
 	fmt.Println("      --allow-errors      Return success even if validation finds issues (payload still emitted)")
 	fmt.Println("  comment-graph check     Validate comment graph consistency")
-	fmt.Println("      --dir <path>        Target a different root")
+	fmt.Println("      --dir <path>        Target a different root (relative path flags resolve against it)")
+	fmt.Println("      --rev <ref>         Scan the tree of a git revision instead of the working tree")
+	fmt.Println("      --ignore <glob>     Skip matching files and directories (repeatable)")
+	fmt.Println("      --follow-symlinks   Scan symlinked files and directories (loops are detected and skipped)")
+	fmt.Println("      --max-file-size <n> Skip files larger than n bytes with a warning (default 10 MiB, -1 for no limit)")
+	fmt.Println("      --max-line-length <n>  Skip files with lines longer than n bytes (default 64 KiB, -1 for no limit)")
+	fmt.Println("      --overlay <file|->  Scan unsaved buffers from a {\"path\": \"contents\"} JSON file or stdin")
+	fmt.Println("      --files <paths...>  Re-scan only these files and merge them into the stored graph")
+	fmt.Println("      --files-from <f|->  Like --files, reading one path per line from a file or stdin")
+	fmt.Println("      --base <path>       Stored graph to merge into (default comment-graph.yml)")
+	fmt.Println("      --config <path>     Rule configuration (default .comment-graph.yml in the root)")
+	fmt.Println("      --against <path>    Also fail if a stored graph (YAML or JSON) differs from the scan, listing the drift (text output only)")
+	fmt.Println("      --format <name>     Output format: text (default), json, sarif, junit, tap, github or gitlab")
+	fmt.Println("      --json              Print only the findings as JSON (same as --format json)")
+	fmt.Println("      --fix               Remove redundant @cgraph-deps entries, then check again")
+	fmt.Println("      --baseline <file>   Only fail on findings not recorded in the baseline; report fixed ones")
+	fmt.Println("      --write-baseline [file]  Record current findings (default --baseline or .comment-graph-baseline.json)")
+	fmt.Println("  comment-graph export    Print the graph as a diagram or for graph tools")
+	fmt.Println("      --format <name>     dot, mermaid, plantuml, d2, graphml, gexf or cytoscape")
+	fmt.Println("      --group <by>        Group nodes by dir (default), file, or none")
+	fmt.Println("      --no-labels         Show node IDs instead of @cgraph-label text")
+	fmt.Println("      --edge-types        Label edges with their type")
+	fmt.Println("      --link-base <url>   Prefix for node links to file:line (default: relative paths)")
+	fmt.Println("      (also accepts the scan flags of graph: --dir, --rev, --ignore, ...)")
+	fmt.Println("  comment-graph report    Write a self-contained interactive HTML report")
+	fmt.Println("      --html <file>       Output file, relative to the root (required)")
+	fmt.Println("      --title <text>      Page title (default: comment-graph: <root directory name>)")
+	fmt.Println("      --link-base <url>   Prefix for node links to file:line (default: relative paths)")
+	fmt.Println("      (also accepts the scan flags of graph: --dir, --rev, --ignore, ...)")
+	fmt.Println("  comment-graph explain [code]  Describe an error code such as CG001 (lists all codes without one)")
+	fmt.Println("  comment-graph schema [name]  Print the JSON Schema of check --json (check) or graph output (graph)")
 	fmt.Println("  comment-graph version   Print the CLI version")
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kuri-sun/comment-graph/internal/engine"
+)
+
+// runReport scans the repository and writes an HTML report to opts.html,
+// resolved against the root.
+// Like export, it describes the graph, so findings do not change the exit
+// code; they are listed in the report instead.
+func runReport(p printer, opts reportFlags) int {
+	root, err := resolveRoot(opts.scan.dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to resolve working directory: %v\n", err)
+		return 1
+	}
+	cfg, err := loadConfig(root, opts.scan)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	scanned, scanErrs, err := scanRepo(root, opts.scan)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan failed: %v\n", err)
+		return 1
+	}
+	report := engine.ValidateGraphWithConfig(scanned, scanErrs, cfg)
+
+	title := opts.title
+	if title == "" {
+		title = "comment-graph: " + filepath.Base(root)
+	}
+	page, err := engine.RenderHTMLReport(scanned, report, title, engine.DiagramOptions{LinkBase: opts.linkBase})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to render report: %v\n", err)
+		return 1
+	}
+	out := resolvePath(root, opts.html)
+	if err := os.WriteFile(out, page, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write report: %v\n", err)
+		return 1
+	}
+	p.okLine(fmt.Sprintf("report written to %s (%d nodes, %d findings)", out, len(scanned.Nodes), len(report.Findings)))
+	return 0
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/kuri-sun/comment-graph/internal/engine"
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// scanRepo builds the graph for root from the source selected by f.
+// Interrupting the process cancels the scan.
+func scanRepo(root string, f scanFlags) (graph.Graph, []engine.ScanError, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := engine.ScanOptions{
+		Ignore:         f.ignore,
+		Concurrency:    runtime.NumCPU(),
+		FollowSymlinks: f.follow,
+		MaxFileSize:    f.maxSize,
+		MaxLineLength:  f.maxLine,
+	}
+	partial := len(f.files) > 0 || f.filesFrom != ""
+	if f.rev != "" {
+		if f.overlay != "" || partial || f.follow {
+			return graph.Graph{}, nil, fmt.Errorf("--rev cannot be combined with --overlay, --files or --follow-symlinks")
+		}
+		return engine.ScanRev(ctx, root, f.rev, opts)
+	}
+	if f.overlay != "" {
+		overlay, err := loadOverlay(root, f.overlay)
+		if err != nil {
+			return graph.Graph{}, nil, err
+		}
+		opts.Overlay = overlay
+	}
+	if partial {
+		files, err := collectFiles(root, f)
+		if err != nil {
+			return graph.Graph{}, nil, err
+		}
+		base, err := engine.ReadGraphFile(basePath(root, f.base))
+		if err != nil {
+			return graph.Graph{}, nil, fmt.Errorf("read base graph: %w", err)
+		}
+		return engine.ScanFiles(ctx, os.DirFS(root), base, files, opts)
+	}
+	return engine.ScanFS(ctx, os.DirFS(root), opts)
+}
+
+func basePath(root, base string) string {
+	if base == "" {
+		return filepath.Join(root, "comment-graph.yml")
+	}
+	return resolvePath(root, base)
+}
+
+// loadConfig reads the --config file, or .comment-graph.yml from root if it exists.
+func loadConfig(root string, f scanFlags) (engine.Config, error) {
+	if f.config == "" {
+		return engine.LoadConfig(root)
+	}
+	return engine.ReadConfigFile(resolvePath(root, f.config))
+}
+
+// resolvePath resolves a path flag against root unless it is absolute. Every
+// path flag goes through it, so relative paths mean the same with --dir.
+func resolvePath(root, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(root, p)
+}
+
+// collectFiles gathers --files and --files-from paths relative to root. The
+// --files-from list is itself resolved against root.
+func collectFiles(root string, f scanFlags) ([]string, error) {
+	names := append([]string{}, f.files...)
+	if f.filesFrom != "" {
+		var data []byte
+		var err error
+		if f.filesFrom == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(resolvePath(root, f.filesFrom))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read file list: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				names = append(names, line)
+			}
+		}
+	}
+
+	files := make([]string, 0, len(names))
+	for _, name := range names {
+		rel, err := relToRoot(root, name)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, rel)
+	}
+	return files, nil
+}
+
+// relToRoot converts an absolute or root-relative path to a slash-separated
+// path relative to root, rejecting paths outside it.
+func relToRoot(root, name string) (string, error) {
+	rel := name
+	if filepath.IsAbs(name) {
+		var err error
+		rel, err = filepath.Rel(root, name)
+		if err != nil {
+			return "", fmt.Errorf("path %q: %w", name, err)
+		}
+	}
+	rel = filepath.ToSlash(filepath.Clean(rel))
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("path %q is outside %s", name, root)
+	}
+	return rel, nil
+}
+
+// loadOverlay reads a {"path": "contents"} object from src ("-" for stdin),
+// which is resolved against root. Paths may be absolute or relative to root
+// and must lie inside root.
+func loadOverlay(root, src string) (map[string][]byte, error) {
+	var data []byte
+	var err error
+	if src == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(resolvePath(root, src))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read overlay: %w", err)
+	}
+
+	var files map[string]string
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("parse overlay: %w", err)
+	}
+
+	overlay := make(map[string][]byte, len(files))
+	for name, contents := range files {
+		rel, err := relToRoot(root, name)
+		if err != nil {
+			return nil, fmt.Errorf("overlay: %w", err)
+		}
+		overlay[rel] = []byte(contents)
+	}
+	return overlay, nil
+}
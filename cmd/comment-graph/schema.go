@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kuri-sun/comment-graph/internal/engine"
+)
+
+// runSchema prints a published JSON Schema, or lists them when no name is
+// given.
+func runSchema(args []string) int {
+	if len(args) == 0 {
+		for _, name := range engine.Schemas() {
+			fmt.Println(name)
+		}
+		return 0
+	}
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "usage: comment-graph schema [name]")
+		return 1
+	}
+	data, err := engine.Schema(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	os.Stdout.Write(data)
+	return 0
+}
@@ -10,91 +10,190 @@ import (
 	"github.com/kuri-sun/comment-graph/internal/graph"
 )
 
-// validateAndReport renders validation errors consistently. Returns (exitCode, failed).
+// validateAndReport renders every failing category at once, grouped by rule
+// and counted. The exit code is that of the most severe category, as given
+// by validationStatus. Returns (exitCode, failed).
 func validateAndReport(p printer, header string, scanned graph.Graph, report engine.CheckReport, fileGraph *graph.Graph, checkDrift bool) (int, bool) {
-	printFailureHeader := func() {
-		fmt.Fprintln(os.Stderr)
-		p.section(header)
-		p.resultLine(false)
-	}
-	printErrorsSection := func() {
-		fmt.Fprintln(os.Stderr)
-		p.section("Errors")
+	code, failed := validationStatus(scanned, report, fileGraph, checkDrift)
+	if !failed {
+		return 0, false
 	}
 
-	ensureHeader := func(done *bool) {
-		if !*done {
-			printFailureHeader()
-			printErrorsSection()
-			*done = true
-		}
-	}
+	fmt.Fprintln(os.Stderr)
+	p.section(header)
+	p.resultLine(false)
+	fmt.Fprintln(os.Stderr)
+	p.section("Errors")
 
-	headerPrinted := false
+	total := 0
+	group := func(name string, items int) {
+		fmt.Fprintf(os.Stderr, "  %s (%d):\n", name, items)
+		total += items
+	}
 
-	if len(report.ScanErrors) > 0 {
-		ensureHeader(&headerPrinted)
+	for _, r := range engine.Rules {
+		var errs []engine.ScanError
 		for _, e := range report.ScanErrors {
-			fmt.Fprintf(os.Stderr, "  - %s:%d: %s\n", e.File, e.Line, e.Msg)
+			if e.Rule == r.ID || (e.Rule == "" && r.ID == engine.RuleInvalidMetadata) {
+				errs = append(errs, e)
+			}
+		}
+		if len(errs) == 0 {
+			continue
+		}
+		group(r.ID, len(errs))
+		for _, e := range errs {
+			fmt.Fprintf(os.Stderr, "    - %s:%d: %s [%s]\n", e.File, e.Line, e.Msg, e.Code)
 		}
-		fmt.Fprintln(os.Stderr)
-		p.warnLine("Fix scan issues and re-run `comment-graph check`.")
-		fmt.Fprintln(os.Stderr)
-		return 3, true
 	}
 
 	if len(report.UndefinedEdges) > 0 {
-		ensureHeader(&headerPrinted)
+		group(engine.RuleUndefinedRef, len(report.UndefinedEdges))
 		for _, e := range report.UndefinedEdges {
 			fromNode, fromOK := scanned.Nodes[e.From]
 			toNode, toOK := scanned.Nodes[e.To]
 			switch {
 			case !fromOK && toOK:
-				fmt.Fprintf(os.Stderr, "  - missing %q (at %s:%d)\n", e.From, toNode.File, toNode.Line)
+				fmt.Fprintf(os.Stderr, "    - missing %q (at %s:%d)\n", e.From, toNode.File, toNode.Line)
 			case fromOK && !toOK:
-				fmt.Fprintf(os.Stderr, "  - missing %q (at %s:%d)\n", e.To, fromNode.File, fromNode.Line)
+				fmt.Fprintf(os.Stderr, "    - missing %q (at %s:%d)\n", e.To, fromNode.File, fromNode.Line)
 			case !fromOK && !toOK:
-				fmt.Fprintf(os.Stderr, "  - missing nodes %q and %q (edge present but ids undefined)\n", e.From, e.To)
+				fmt.Fprintf(os.Stderr, "    - missing nodes %q and %q (edge present but ids undefined)\n", e.From, e.To)
 			default:
-				fmt.Fprintf(os.Stderr, "  - undefined node reference: %s -> %s\n", e.From, e.To)
+				fmt.Fprintf(os.Stderr, "    - undefined node reference: %s -> %s\n", e.From, e.To)
 			}
 		}
-		fmt.Fprintln(os.Stderr)
-		return 1, true
 	}
 
-	if len(report.Cycles) > 0 {
-		ensureHeader(&headerPrinted)
-		fmt.Fprintln(os.Stderr, "  - cycles detected:")
-		for _, c := range report.Cycles {
-			fmt.Fprintf(os.Stderr, "    cycle: %s\n", strings.Join(c, " -> "))
+	// A finding covers one strongly connected component, which may hold
+	// several elementary cycles; count and list the findings.
+	var cycles []engine.Finding
+	for _, f := range report.Findings {
+		if f.Rule == engine.RuleCycle && f.Severity == engine.SeverityError {
+			cycles = append(cycles, f)
+		}
+	}
+	if len(cycles) > 0 {
+		group(engine.RuleCycle, len(cycles))
+		for _, f := range cycles {
+			fmt.Fprintf(os.Stderr, "    - %s\n", f.Message)
+			if f.Suggestion != "" {
+				fmt.Fprintf(os.Stderr, "      hint: %s\n", f.Suggestion)
+			}
 		}
-		fmt.Fprintln(os.Stderr)
-		return 2, true
 	}
 
-	mismatch := false
 	if len(report.Isolated) > 0 {
-		ensureHeader(&headerPrinted)
-		fmt.Fprintf(os.Stderr, "  - isolated nodes: %s\n", strings.Join(report.Isolated, ", "))
-		mismatch = true
+		group(engine.RuleIsolated, len(report.Isolated))
+		for _, id := range report.Isolated {
+			n := scanned.Nodes[id]
+			fmt.Fprintf(os.Stderr, "    - isolated node %s (%s:%d)\n", id, n.File, n.Line)
+		}
 	}
 
-	if checkDrift && fileGraph != nil && !engine.GraphsEqual(scanned, *fileGraph) {
-		ensureHeader(&headerPrinted)
-		fmt.Fprintln(os.Stderr, "  - comment-graph.yml is out of date")
-		mismatch = true
+	ruleErrs := report.RuleErrors()
+	for i := 0; i < len(ruleErrs); {
+		j := i
+		for j < len(ruleErrs) && ruleErrs[j].Rule == ruleErrs[i].Rule {
+			j++
+		}
+		group(ruleErrs[i].Rule, j-i)
+		for _, f := range ruleErrs[i:j] {
+			fmt.Fprintf(os.Stderr, "    - %s [%s]\n", f.Message, f.Code)
+			for _, l := range f.Locations {
+				fmt.Fprintf(os.Stderr, "        at %s (%s)\n", formatLocation(l), l.Node)
+			}
+			if f.Suggestion != "" {
+				fmt.Fprintf(os.Stderr, "        hint: %s\n", f.Suggestion)
+			}
+		}
+		i = j
 	}
 
-	if mismatch {
-		fmt.Fprintln(os.Stderr)
-		return 3, true
+	if checkDrift && fileGraph != nil {
+		if drift := engine.DiffGraphs(*fileGraph, scanned); !drift.Empty() {
+			lines := driftLines(drift)
+			fmt.Fprintf(os.Stderr, "  stored graph is out of date (%d):\n", len(lines))
+			total += len(lines)
+			for _, line := range lines {
+				fmt.Fprintf(os.Stderr, "    %s\n", line)
+			}
+		}
 	}
 
-	return 0, false
+	fmt.Fprintln(os.Stderr)
+	p.errLine(fmt.Sprintf("%d %s", total, plural(total, "problem", "problems")))
+	if len(report.ScanErrors) > 0 {
+		p.warnLine("Fix scan issues and re-run `comment-graph check`.")
+	}
+	fmt.Fprintln(os.Stderr)
+	return code, true
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// driftLines renders a drift list, one change per line.
+func driftLines(d engine.GraphDrift) []string {
+	var lines []string
+	for _, n := range d.AddedNodes {
+		lines = append(lines, fmt.Sprintf("+ node %s (%s:%d)", n.ID, n.File, n.Line))
+	}
+	for _, n := range d.RemovedNodes {
+		lines = append(lines, fmt.Sprintf("- node %s (%s:%d)", n.ID, n.File, n.Line))
+	}
+	for _, m := range d.MovedNodes {
+		lines = append(lines, fmt.Sprintf("~ node %s moved %s -> %s", m.ID, formatLocation(m.From), formatLocation(m.To)))
+	}
+	for _, c := range d.LabelChanges {
+		lines = append(lines, fmt.Sprintf("~ node %s label %q -> %q", c.ID, c.From, c.To))
+	}
+	for _, c := range d.TagChanges {
+		lines = append(lines, fmt.Sprintf("~ node %s tags [%s] -> [%s]", c.ID, strings.Join(c.From, ", "), strings.Join(c.To, ", ")))
+	}
+	for _, c := range d.IgnoreChanges {
+		lines = append(lines, fmt.Sprintf("~ node %s ignore [%s] -> [%s]", c.ID, strings.Join(c.From, ", "), strings.Join(c.To, ", ")))
+	}
+	for _, e := range d.AddedEdges {
+		lines = append(lines, fmt.Sprintf("+ edge %s -> %s", e.From, e.To))
+	}
+	for _, e := range d.RemovedEdges {
+		lines = append(lines, fmt.Sprintf("- edge %s -> %s", e.From, e.To))
+	}
+	return lines
+}
+
+// reportWarnings prints findings with warn severity; they never fail a command.
+func reportWarnings(p printer, findings []engine.Finding) {
+	for _, f := range findings {
+		if f.Severity != engine.SeverityWarn {
+			continue
+		}
+		msg := fmt.Sprintf("%s [%s]", f.Message, f.Rule)
+		if len(f.Locations) > 0 {
+			msg = fmt.Sprintf("%s: %s", formatLocation(f.Locations[0]), msg)
+		}
+		if f.Suggestion != "" {
+			msg += " (" + f.Suggestion + ")"
+		}
+		p.warnLine(msg)
+	}
+}
+
+func formatLocation(l engine.Location) string {
+	if l.Line > 0 {
+		return fmt.Sprintf("%s:%d", l.File, l.Line)
+	}
+	return l.File
 }
 
-// validationStatus mirrors validateAndReport's exit codes without rendering.
+// validationStatus returns the exit code of the most severe failing category:
+// 3 for scan errors, 1 for undefined references, 2 for cycles, then 3 for any
+// other error finding or a stale stored graph.
 func validationStatus(scanned graph.Graph, report engine.CheckReport, fileGraph *graph.Graph, checkDrift bool) (int, bool) {
 	if len(report.ScanErrors) > 0 {
 		return 3, true
@@ -105,8 +204,8 @@ func validationStatus(scanned graph.Graph, report engine.CheckReport, fileGraph
 	if len(report.Cycles) > 0 {
 		return 2, true
 	}
-	mismatch := len(report.Isolated) > 0
-	if checkDrift && fileGraph != nil && !engine.GraphsEqual(scanned, *fileGraph) {
+	mismatch := len(report.Isolated) > 0 || len(report.RuleErrors()) > 0
+	if checkDrift && fileGraph != nil && !engine.GraphsEqual(*fileGraph, scanned) {
 		mismatch = true
 	}
 	if mismatch {
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -23,10 +24,10 @@ type graphPayload struct {
 		} `json:"edges"`
 	} `json:"graph"`
 	Report struct {
-		ScanErrors     []struct{ Msg string }      `json:"scanErrors"`
-		UndefinedEdges []struct{ From, To string } `json:"undefinedEdges"`
-		Cycles         [][]string                  `json:"cycles"`
-		Isolated       []string                    `json:"isolated"`
+		ScanErrors     []struct{ Msg, Code string } `json:"scanErrors"`
+		UndefinedEdges []struct{ From, To string }  `json:"undefinedEdges"`
+		Cycles         [][]string                   `json:"cycles"`
+		Isolated       []string                     `json:"isolated"`
 	} `json:"report"`
 }
 
@@ -151,6 +152,148 @@ func TestCLICheckDetectsCycle(t *testing.T) {
 	}
 }
 
+func TestCLICheckReportsEveryCategory(t *testing.T) {
+	tmp := t.TempDir()
+	src := "// @cgraph-deps x\n\n// @cgraph-id a\n// @cgraph-deps b, missing\n\n// @cgraph-id b\n// @cgraph-deps a\n\n// @cgraph-id lonely\n"
+	if err := os.WriteFile(filepath.Join(tmp, "a.go"), []byte(src), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 3, "check")
+	for _, want := range []string{
+		"invalid-metadata (1):",
+		"undefined-ref (1):",
+		"cycle (1):",
+		"    - cycle: a -> b -> a",
+		"isolated (1):",
+		"4 problems",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestCLICheckCountsCycleFindings(t *testing.T) {
+	tmp := t.TempDir()
+	src := "// @cgraph-id a\n// @cgraph-deps b\n\n// @cgraph-id b\n// @cgraph-deps a, c\n\n// @cgraph-id c\n// @cgraph-deps b\n"
+	if err := os.WriteFile(filepath.Join(tmp, "a.go"), []byte(src), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 2, "check")
+	for _, want := range []string{"cycle (1):", "    - 2 cycles among a, b, c", "1 problem"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestCLICheckFormatSARIF(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("cycle", "a.ts"), tmp)
+	copyFixtureFile(t, filepath.Join("cycle", "b.ts"), tmp)
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 2, "check", "--format", "sarif")
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID string `json:"ruleId"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal([]byte(out), &log); err != nil {
+		t.Fatalf("decode sarif: %v\nout:\n%s", err, out)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 || log.Runs[0].Results[0].RuleID != "CG011" {
+		t.Fatalf("expected one cycle result, got:\n%s", out)
+	}
+
+	runCmdExpectExit(t, bin, tmp, 1, "check", "--format", "xml")
+}
+
+func TestCLICheckFormatJUnitAndTAP(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("cycle", "a.ts"), tmp)
+	copyFixtureFile(t, filepath.Join("cycle", "b.ts"), tmp)
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 2, "check", "--format", "junit")
+	if !strings.HasPrefix(out, "<?xml") || !strings.Contains(out, `<testsuite name="cycle" tests="2" failures="2">`) {
+		t.Fatalf("expected junit report with failing cycle cases, got:\n%s", out)
+	}
+	_, out = runCmdExpectExit(t, bin, tmp, 2, "check", "--format", "tap")
+	if !strings.HasPrefix(out, "TAP version 13\n") || !strings.Contains(out, "not ok") || !strings.Contains(out, "code: CG011") {
+		t.Fatalf("expected tap report with failing cycle points, got:\n%s", out)
+	}
+}
+
+func TestCLICheckFormatCIAnnotations(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("isolated", "index.ts"), tmp)
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 3, "check", "--format", "github")
+	if !strings.HasPrefix(out, "::error file=isolated/index.ts,line=") || !strings.Contains(out, "title=CG012 isolated::") {
+		t.Fatalf("expected github error annotation, got:\n%s", out)
+	}
+
+	_, out = runCmdExpectExit(t, bin, tmp, 3, "check", "--format", "gitlab")
+	var issues []struct {
+		CheckName   string `json:"check_name"`
+		Fingerprint string `json:"fingerprint"`
+		Location    struct {
+			Path string `json:"path"`
+		} `json:"location"`
+	}
+	if err := json.Unmarshal([]byte(out), &issues); err != nil {
+		t.Fatalf("decode code quality report: %v\nout:\n%s", err, out)
+	}
+	if len(issues) == 0 || issues[0].CheckName != "CG012" || issues[0].Fingerprint == "" || issues[0].Location.Path != "isolated/index.ts" {
+		t.Fatalf("unexpected code quality report:\n%s", out)
+	}
+}
+
+func TestCLICheckJSONAndSchema(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("isolated", "index.ts"), tmp)
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 3, "check", "--json")
+	var report struct {
+		Version  int `json:"version"`
+		Findings []struct {
+			Code      string   `json:"code"`
+			Severity  string   `json:"severity"`
+			Message   string   `json:"message"`
+			Nodes     []string `json:"nodes"`
+			Locations []struct {
+				File string `json:"file"`
+				Line int    `json:"line"`
+			} `json:"locations"`
+		} `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("decode check --json: %v\nout:\n%s", err, out)
+	}
+	if report.Version != 1 || len(report.Findings) == 0 || report.Findings[0].Code != "CG012" || len(report.Findings[0].Locations) != 1 {
+		t.Fatalf("unexpected findings:\n%s", out)
+	}
+
+	for _, name := range []string{"check", "graph"} {
+		_, out = runCmdExpectExit(t, bin, tmp, 0, "schema", name)
+		var schema map[string]any
+		if err := json.Unmarshal([]byte(out), &schema); err != nil || schema["$schema"] == nil {
+			t.Fatalf("expected %s JSON Schema, got %v:\n%s", name, err, out)
+		}
+	}
+	runCmdExpectExit(t, bin, tmp, 1, "schema", "nope")
+}
+
 func TestCLICheckDetectsIsolated(t *testing.T) {
 	tmp := t.TempDir()
 	copyFixtureFile(t, filepath.Join("isolated", "index.ts"), tmp)
@@ -160,11 +303,492 @@ func TestCLICheckDetectsIsolated(t *testing.T) {
 	if code != 3 {
 		t.Fatalf("expected exit 3, got %d\nout:\n%s", code, out)
 	}
-	if !strings.Contains(out, "isolated nodes") {
+	if !strings.Contains(out, "isolated (") || !strings.Contains(out, "isolated node ") {
 		t.Fatalf("expected isolated nodes output, got:\n%s", out)
 	}
 }
 
+func TestCLICheckConfigDowngradesRule(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("isolated", "index.ts"), tmp)
+	if err := os.WriteFile(filepath.Join(tmp, ".comment-graph.yml"), []byte("rules:\n  isolated: warn\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 0, "check")
+	if !strings.Contains(out, "[isolated]") {
+		t.Fatalf("expected isolated warning, got:\n%s", out)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, "strict.yml"), []byte("rules:\n  isolated: nope\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	_, out = runCmdExpectExit(t, bin, tmp, 1, "check", "--config", "strict.yml")
+	if !strings.Contains(out, "invalid severity") {
+		t.Fatalf("expected config error, got:\n%s", out)
+	}
+}
+
+func TestCLICheckPolicies(t *testing.T) {
+	tmp := t.TempDir()
+	files := map[string]string{
+		"web/page.ts":        "// @cgraph-id page\n// @cgraph-deps migrate\n",
+		"migrations/001.sql": "-- @cgraph-id migrate\n",
+		".comment-graph.yml": "policies:\n  - name: web-not-migrations\n    nodes: {path: \"web/**\"}\n    deny: {path: \"migrations/**\"}\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(tmp, name)), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 3, "check")
+	if !strings.Contains(out, "policy (1):") || !strings.Contains(out, "web-not-migrations [CG013]") || !strings.Contains(out, "at migrations/001.sql:1 (migrate)") {
+		t.Fatalf("expected policy violation with both locations, got:\n%s", out)
+	}
+}
+
+func TestCLICheckLimits(t *testing.T) {
+	tmp := t.TempDir()
+	files := map[string]string{
+		"jobs.go":            "// @cgraph-id a\n\n// @cgraph-id b\n// @cgraph-deps a\n\n// @cgraph-id c\n// @cgraph-deps b\n\n// @cgraph-id d\n// @cgraph-deps a, c\n",
+		".comment-graph.yml": "limits:\n  max-depth: 2\n  max-fan-in: 1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 3, "check")
+	for _, want := range []string{"max-depth (1):", "[CG014]", "max-fan-in (1):", "[CG015]"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "max-fan-out") {
+		t.Fatalf("expected unset max-fan-out to stay off, got:\n%s", out)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, ".comment-graph.yml"), []byte("limits:\n  max-depth: many\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	_, out = runCmdExpectExit(t, bin, tmp, 1, "check")
+	if !strings.Contains(out, "max-depth") {
+		t.Fatalf("expected invalid limit to be reported, got:\n%s", out)
+	}
+}
+
+func TestCLICheckFixRemovesRedundantDeps(t *testing.T) {
+	tmp := t.TempDir()
+	content := "// @cgraph-id a\n\n// @cgraph-id b\n// @cgraph-deps a\n\n// @cgraph-id c\n// @cgraph-deps a, b\n"
+	path := filepath.Join(tmp, "plan.ts")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 0, "check")
+	if !strings.Contains(out, `"c" already depends on "a" through a -> b -> c [redundant-dep]`) {
+		t.Fatalf("expected redundant-dep warning, got:\n%s", out)
+	}
+
+	_, out = runCmdExpectExit(t, bin, tmp, 0, "check", "--fix")
+	if !strings.Contains(out, `removed "a" from the @cgraph-deps of "c"`) || strings.Contains(out, "[redundant-dep]") {
+		t.Fatalf("expected fix without remaining warnings, got:\n%s", out)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	if !strings.HasSuffix(string(data), "// @cgraph-id c\n// @cgraph-deps b\n") {
+		t.Fatalf("unexpected rewrite:\n%s", data)
+	}
+}
+
+func TestCLICheckFixFailsWhenDepsCannotBeRewritten(t *testing.T) {
+	tmp := t.TempDir()
+	content := "// @cgraph-id a\n\n// @cgraph-id b\n// @cgraph-deps a\n\n// @cgraph-id c\n// @cgraph-deps a\n// @cgraph-deps b\n"
+	path := filepath.Join(tmp, "plan.ts")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 1, "check", "--fix")
+	for _, want := range []string{"could not update c: multiple @cgraph-deps entries", "[redundant-dep]", "failed"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "removed ") || strings.Contains(out, "succeeded") {
+		t.Fatalf("expected nothing to be reported as fixed, got:\n%s", out)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != content {
+		t.Fatalf("expected the file to be left unchanged, got %q (%v)", data, err)
+	}
+}
+
+func TestCLICheckAgainstReportsDrift(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("sample", "index.ts"), tmp)
+	copyFixtureFile(t, filepath.Join("sample", "users.ts"), tmp)
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 0, "graph")
+	if err := os.WriteFile(filepath.Join(tmp, "stored.json"), []byte(out), 0o644); err != nil {
+		t.Fatalf("write stored graph: %v", err)
+	}
+	runCmdExpectExit(t, bin, tmp, 0, "check", "--against", "stored.json")
+
+	users := filepath.Join(tmp, "sample", "users.ts")
+	data, err := os.ReadFile(users)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	if err := os.WriteFile(users, append([]byte("\n\n"), data...), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	_, out = runCmdExpectExit(t, bin, tmp, 3, "check", "--against", "stored.json")
+	if !strings.Contains(out, "stored graph is out of date") || !strings.Contains(out, "~ node db-sample moved sample/users.ts:") {
+		t.Fatalf("expected drift list, got:\n%s", out)
+	}
+
+	for _, args := range [][]string{{"--json"}, {"--format", "sarif"}} {
+		_, out = runCmdExpectExit(t, bin, tmp, 1, append([]string{"check", "--against", "stored.json"}, args...)...)
+		if !strings.Contains(out, "--against reports drift as text") {
+			t.Fatalf("expected --against %v to be rejected, got:\n%s", args, out)
+		}
+	}
+}
+
+func TestCLICheckInlineIgnore(t *testing.T) {
+	tmp := t.TempDir()
+	content := "// @cgraph-id lonely\n// @cgraph-ignore isolated\n\n// @cgraph-id a\n// @cgraph-ignore cycle\n\n// @cgraph-id b\n// @cgraph-deps a\n"
+	if err := os.WriteFile(filepath.Join(tmp, "notes.ts"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 0, "check")
+	if !strings.Contains(out, `cycle on "a" does not suppress anything [unused-suppression]`) || strings.Contains(out, `"lonely"`) {
+		t.Fatalf("expected only the cycle ignore to be reported unused, got:\n%s", out)
+	}
+}
+
+func TestCLICheckBaseline(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("isolated", "index.ts"), tmp)
+
+	bin := buildCLI(t)
+	runCmdExpectExit(t, bin, tmp, 0, "check", "--write-baseline")
+	if _, err := os.Stat(filepath.Join(tmp, ".comment-graph-baseline.json")); err != nil {
+		t.Fatalf("baseline not written: %v", err)
+	}
+	runCmdExpectExit(t, bin, tmp, 0, "check", "--baseline", ".comment-graph-baseline.json")
+
+	if err := os.WriteFile(filepath.Join(tmp, "isolated", "index.ts"), []byte("// @cgraph-id fresh\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	_, out := runCmdExpectExit(t, bin, tmp, 3, "check", "--baseline", ".comment-graph-baseline.json")
+	if !strings.Contains(out, "isolated node fresh") || !strings.Contains(out, "fixed: isolated in isolated/index.ts (lonely)") {
+		t.Fatalf("expected new finding and fixed entry, got:\n%s", out)
+	}
+}
+
+func TestCLIGraphRevReadsCommittedTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("sample", "index.ts"), tmp)
+	copyFixtureFile(t, filepath.Join("sample", "users.ts"), tmp)
+	runGit(t, tmp, "init", "-q")
+	runGit(t, tmp, "add", "-A")
+	runGit(t, tmp, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "fixtures")
+	if err := os.Remove(filepath.Join(tmp, "sample", "users.ts")); err != nil {
+		t.Fatalf("remove fixture: %v", err)
+	}
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 0, "graph", "--rev", "HEAD")
+	payload := decodeGraph(t, out)
+	if !hasEdge(payload.Graph.Edges, "db-sample", "cache-sample") {
+		t.Fatalf("expected committed edges in --rev output: %+v", payload.Graph.Edges)
+	}
+
+	runCmdExpectExit(t, bin, tmp, 0, "check", "--rev", "HEAD")
+}
+
+func TestCLIGraphOverlayUsesUnsavedContents(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("sample", "index.ts"), tmp)
+	copyFixtureFile(t, filepath.Join("sample", "users.ts"), tmp)
+
+	overlay := map[string]string{
+		filepath.Join(tmp, "sample", "index.ts"): "// @cgraph-id cleanup-sample\n// @cgraph-deps db-sample\n",
+	}
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		t.Fatalf("marshal overlay: %v", err)
+	}
+	overlayPath := filepath.Join(t.TempDir(), "overlay.json")
+	if err := os.WriteFile(overlayPath, data, 0o644); err != nil {
+		t.Fatalf("write overlay: %v", err)
+	}
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 0, "graph", "--overlay", overlayPath)
+	payload := decodeGraph(t, out)
+	if !hasEdge(payload.Graph.Edges, "db-sample", "cleanup-sample") {
+		t.Fatalf("expected edge from overlay contents: %+v", payload.Graph.Edges)
+	}
+	if hasEdge(payload.Graph.Edges, "cache-sample", "cleanup-sample") {
+		t.Fatalf("on-disk edge should be replaced by overlay: %+v", payload.Graph.Edges)
+	}
+}
+
+func TestCLIGraphFilesMergesIntoBase(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("sample", "index.ts"), tmp)
+	copyFixtureFile(t, filepath.Join("sample", "users.ts"), tmp)
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 0, "graph")
+	basePath := filepath.Join(t.TempDir(), "base.json")
+	if err := os.WriteFile(basePath, []byte(out), 0o644); err != nil {
+		t.Fatalf("write base: %v", err)
+	}
+
+	index := filepath.Join("sample", "index.ts")
+	if err := os.WriteFile(filepath.Join(tmp, index), []byte("// @cgraph-id cleanup-sample\n// @cgraph-deps gone\n"), 0o644); err != nil {
+		t.Fatalf("rewrite fixture: %v", err)
+	}
+
+	_, out = runCmdExpectExit(t, bin, tmp, 1, "graph", "--files", index, "--base", basePath)
+	payload := decodeGraph(t, out)
+	if !hasEdge(payload.Graph.Edges, "db-sample", "cache-sample") {
+		t.Fatalf("expected edges from untouched files to be kept: %+v", payload.Graph.Edges)
+	}
+	if hasEdge(payload.Graph.Edges, "cache-sample", "cleanup-sample") {
+		t.Fatalf("expected re-scanned edges to replace stored ones: %+v", payload.Graph.Edges)
+	}
+	if len(payload.Report.UndefinedEdges) != 1 {
+		t.Fatalf("expected merged graph to be validated, got %+v", payload.Report)
+	}
+}
+
+func TestCLIResolvesPathFlagsAgainstDir(t *testing.T) {
+	root := t.TempDir()
+	copyFixtureFile(t, filepath.Join("sample", "index.ts"), root)
+	copyFixtureFile(t, filepath.Join("sample", "users.ts"), root)
+	elsewhere := t.TempDir()
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, elsewhere, 0, "graph", "--dir", root)
+	files := map[string]string{
+		"base.json":    out,
+		"list.txt":     "sample/index.ts\n",
+		"overlay.json": `{"sample/index.ts": "// @cgraph-id cleanup-sample\n// @cgraph-deps gone\n"}`,
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(contents), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	_, out = runCmdExpectExit(t, bin, elsewhere, 0, "graph", "--dir", root, "--files-from", "list.txt", "--base", "base.json")
+	if payload := decodeGraph(t, out); !hasEdge(payload.Graph.Edges, "cache-sample", "cleanup-sample") {
+		t.Fatalf("expected the graph merged from the root's list and base: %+v", payload.Graph.Edges)
+	}
+	_, out = runCmdExpectExit(t, bin, elsewhere, 1, "graph", "--dir", root, "--overlay", "overlay.json")
+	if payload := decodeGraph(t, out); len(payload.Report.UndefinedEdges) != 1 {
+		t.Fatalf("expected the overlay from the root to be scanned: %+v", payload.Report)
+	}
+	runCmdExpectExit(t, bin, elsewhere, 0, "report", "--dir", root, "--html", "report.html")
+	if _, err := os.Stat(filepath.Join(root, "report.html")); err != nil {
+		t.Fatalf("expected the report in the root: %v", err)
+	}
+}
+
+func TestCLIRejectsZeroScanLimits(t *testing.T) {
+	bin := buildCLI(t)
+	dir := t.TempDir()
+	for _, flag := range []string{"--max-file-size", "--max-line-length"} {
+		_, out := runCmdExpectExit(t, bin, dir, 1, "check", flag, "0")
+		if !strings.Contains(out, "-1 for no limit") {
+			t.Fatalf("expected %s 0 to be rejected, got:\n%s", flag, out)
+		}
+	}
+}
+
+func TestCLICheckFollowSymlinks(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("sample", "index.ts"), tmp)
+	copyFixtureFile(t, filepath.Join("sample", "users.ts"), tmp)
+	if err := os.MkdirAll(filepath.Join(tmp, "app"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.Symlink(filepath.Join("..", "sample"), filepath.Join(tmp, "app", "sample")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink("..", filepath.Join(tmp, "app", "up")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 0, "graph", "--follow-symlinks")
+	payload := decodeGraph(t, out)
+	if got := payload.Graph.Nodes["db-sample"].File; got != filepath.Join("sample", "users.ts") {
+		t.Fatalf("expected node reported under its real path, got %q", got)
+	}
+
+	_, out = runCmdExpectExit(t, bin, tmp, 0, "graph", "--follow-symlinks", "--ignore", "/sample")
+	payload = decodeGraph(t, out)
+	if got := payload.Graph.Nodes["db-sample"].File; got != filepath.Join("app", "sample", "users.ts") {
+		t.Fatalf("expected ignored directory reached through symlink, got %q", got)
+	}
+
+	_, out = runCmdExpectExit(t, bin, tmp, 0, "check", "--follow-symlinks")
+	if !strings.Contains(out, "loop") {
+		t.Fatalf("expected loop warning, got:\n%s", out)
+	}
+}
+
+func TestCLIExplainCommand(t *testing.T) {
+	bin := buildCLI(t)
+	dir := t.TempDir()
+
+	_, out := runCmdExpectExit(t, bin, dir, 0, "explain", "CG001")
+	for _, want := range []string{"CG001: metadata without @cgraph-id", "Example:", "Fix:"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in explain output, got:\n%s", want, out)
+		}
+	}
+	_, out = runCmdExpectExit(t, bin, dir, 0, "explain")
+	if !strings.Contains(out, "CG011  cycle") {
+		t.Fatalf("expected code list, got:\n%s", out)
+	}
+	runCmdExpectExit(t, bin, dir, 1, "explain", "CG999")
+
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("// @cgraph-deps b\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	_, out = runCmdExpectExit(t, bin, dir, 0, "graph", "--allow-errors")
+	payload := decodeGraph(t, out)
+	if len(payload.Report.ScanErrors) != 1 || payload.Report.ScanErrors[0].Code != "CG001" {
+		t.Fatalf("expected CG001 scan error in payload, got %+v", payload.Report.ScanErrors)
+	}
+}
+
+func TestCLIExportDOT(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("cycle", "a.ts"), tmp)
+	copyFixtureFile(t, filepath.Join("cycle", "b.ts"), tmp)
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 0, "export", "--format", "dot")
+	if !strings.HasPrefix(out, "digraph comment_graph {") || !strings.Contains(out, `label="cycle";`) || !strings.Contains(out, "color=red") {
+		t.Fatalf("expected clustered dot output with a red cycle, got:\n%s", out)
+	}
+	runCmdExpectExit(t, bin, tmp, 1, "export")
+	runCmdExpectExit(t, bin, tmp, 1, "export", "--format", "dot", "--group", "tags")
+}
+
+func TestCLIExportMermaid(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("isolated", "index.ts"), tmp)
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 0, "export", "--format", "mermaid", "--link-base", "https://example.com/")
+	if !strings.HasPrefix(out, "graph TD\n") || !strings.Contains(out, `subgraph g0["isolated"]`) {
+		t.Fatalf("expected grouped mermaid output, got:\n%s", out)
+	}
+	if !strings.Contains(out, `click n1 href "https://example.com/isolated/index.ts#L`) || !strings.Contains(out, " error\n") {
+		t.Fatalf("expected links and error classes, got:\n%s", out)
+	}
+}
+
+func TestCLIExportPlantUMLAndD2(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("cycle", "a.ts"), tmp)
+	copyFixtureFile(t, filepath.Join("cycle", "b.ts"), tmp)
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 0, "export", "--format", "plantuml")
+	if !strings.HasPrefix(out, "@startuml\n") || !strings.Contains(out, `package "cycle" {`) || !strings.Contains(out, "-[#d33]->") {
+		t.Fatalf("expected packaged plantuml output with a red cycle, got:\n%s", out)
+	}
+	_, out = runCmdExpectExit(t, bin, tmp, 0, "export", "--format", "d2", "--edge-types")
+	if !strings.Contains(out, `g1: "cycle" {`) || !strings.Contains(out, `: "blocks" {style: {stroke: "#d33"}}`) {
+		t.Fatalf("expected d2 containers with a red typed cycle edge, got:\n%s", out)
+	}
+}
+
+func TestCLIExportAnalysisFormats(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("cycle", "a.ts"), tmp)
+	copyFixtureFile(t, filepath.Join("cycle", "b.ts"), tmp)
+
+	bin := buildCLI(t)
+	_, out := runCmdExpectExit(t, bin, tmp, 0, "export", "--format", "graphml")
+	if !strings.Contains(out, "<graphml") || !strings.Contains(out, `<data key="file">cycle/a.ts</data>`) {
+		t.Fatalf("expected graphml with node files, got:\n%s", out)
+	}
+	_, out = runCmdExpectExit(t, bin, tmp, 0, "export", "--format", "gexf")
+	if !strings.Contains(out, `<gexf xmlns="http://gexf.net/1.3"`) || !strings.Contains(out, `label="blocks"`) {
+		t.Fatalf("expected gexf with typed edges, got:\n%s", out)
+	}
+	_, out = runCmdExpectExit(t, bin, tmp, 0, "export", "--format", "cytoscape")
+	var doc struct {
+		Elements struct {
+			Nodes []struct {
+				Data map[string]any `json:"data"`
+			} `json:"nodes"`
+			Edges []struct {
+				Data map[string]any `json:"data"`
+			} `json:"edges"`
+		} `json:"elements"`
+	}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("decode cytoscape output: %v\n%s", err, out)
+	}
+	if len(doc.Elements.Nodes) == 0 || doc.Elements.Nodes[0].Data["state"] != "error" || len(doc.Elements.Edges) == 0 {
+		t.Fatalf("expected cytoscape nodes in error state and edges, got:\n%s", out)
+	}
+}
+
+func TestCLIReportHTML(t *testing.T) {
+	tmp := t.TempDir()
+	copyFixtureFile(t, filepath.Join("cycle", "a.ts"), tmp)
+	copyFixtureFile(t, filepath.Join("cycle", "b.ts"), tmp)
+
+	bin := buildCLI(t)
+	out := filepath.Join(tmp, "out", "report.html")
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_, stdout := runCmdExpectExit(t, bin, tmp, 0, "report", "--html", out, "--title", "Plans")
+	if !strings.Contains(stdout, "report written to") {
+		t.Fatalf("expected confirmation, got:\n%s", stdout)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	page := string(data)
+	if !strings.HasPrefix(page, "<!DOCTYPE html>") || !strings.Contains(page, "<title>Plans</title>") || !strings.Contains(page, `"cyclic":true`) {
+		t.Fatalf("expected an html report with the cycle, got:\n%s", page)
+	}
+	runCmdExpectExit(t, bin, tmp, 1, "report")
+}
+
 func TestCLIVersionCommand(t *testing.T) {
 	bin := buildCLI(t)
 	dir := t.TempDir()
@@ -221,18 +845,41 @@ func absPath(t *testing.T, p string) string {
 	return abs
 }
 
+// cli holds the binary shared by every test; it is built on first use from
+// the working tree with an empty build cache.
+var cli struct {
+	once sync.Once
+	dir  string
+	bin  string
+	out  []byte
+	err  error
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if cli.dir != "" {
+		os.RemoveAll(cli.dir)
+	}
+	os.Exit(code)
+}
+
 func buildCLI(t *testing.T) string {
 	t.Helper()
 	root := findModuleRoot(t)
-	bin := filepath.Join(t.TempDir(), "comment-graph")
-	cmd := exec.Command("go", "build", "-o", bin, "./cmd/comment-graph")
-	cmd.Dir = root
-	cmd.Env = append(os.Environ(), "GOCACHE="+t.TempDir())
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		t.Fatalf("build failed: %v\nout:\n%s", err, string(out))
-	}
-	return bin
+	cli.once.Do(func() {
+		if cli.dir, cli.err = os.MkdirTemp("", "comment-graph-cli"); cli.err != nil {
+			return
+		}
+		cli.bin = filepath.Join(cli.dir, "comment-graph")
+		cmd := exec.Command("go", "build", "-o", cli.bin, "./cmd/comment-graph")
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GOCACHE="+filepath.Join(cli.dir, "cache"))
+		cli.out, cli.err = cmd.CombinedOutput()
+	})
+	if cli.err != nil {
+		t.Fatalf("build failed: %v\nout:\n%s", cli.err, string(cli.out))
+	}
+	return cli.bin
 }
 
 func runCmd(t *testing.T, bin, dir string, args ...string) {
@@ -246,6 +893,17 @@ func runCmd(t *testing.T, bin, dir string, args ...string) {
 	}
 }
 
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\nout:\n%s", strings.Join(args, " "), err, string(out))
+	}
+}
+
 func runCmdExpectExit(t *testing.T, bin, dir string, expect int, args ...string) (int, string) {
 	t.Helper()
 	cmd := exec.Command(bin, args...)
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// RenderGitHubAnnotations renders each finding as a GitHub Actions workflow
+// command (::error or ::warning) so it shows inline on pull requests. The
+// annotation points at the finding's primary location and is titled with
+// its code and rule.
+func RenderGitHubAnnotations(report CheckReport) string {
+	var b strings.Builder
+	for _, f := range report.Findings {
+		command := "error"
+		if f.Severity == SeverityWarn {
+			command = "warning"
+		}
+		var props []string
+		if len(f.Locations) > 0 {
+			l := f.Locations[0]
+			props = append(props, "file="+githubProperty(filepath.ToSlash(l.File)))
+			if l.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", l.Line))
+			}
+		}
+		props = append(props, "title="+githubProperty(fmt.Sprintf("%s %s", f.Code, f.Rule)))
+		msg := f.Message
+		if f.Suggestion != "" {
+			msg += "\n" + f.Suggestion
+		}
+		fmt.Fprintf(&b, "::%s %s::%s\n", command, strings.Join(props, ","), githubData(msg))
+	}
+	return b.String()
+}
+
+// githubData escapes a workflow command message.
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubProperty escapes a workflow command property value.
+func githubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// codeQualityIssue is an entry of a GitLab Code Quality report.
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+// RenderGitLabCodeQuality renders the findings as a GitLab Code Quality
+// report. Fingerprints hash the code, primary file and nodes of a finding,
+// as baselines do, so they survive line changes and let GitLab tell new
+// findings from fixed ones; findings sharing that key are told apart by
+// their order.
+func RenderGitLabCodeQuality(report CheckReport) ([]byte, error) {
+	issues := []codeQualityIssue{}
+	seen := make(map[baselineKey]int)
+	for _, f := range report.Findings {
+		k := keyOf(f)
+		n := seen[k]
+		seen[k]++
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%d", f.Code, k.file, k.nodes, n)))
+
+		issue := codeQualityIssue{
+			Description: f.Message,
+			CheckName:   f.Code,
+			Fingerprint: hex.EncodeToString(sum[:]),
+			Severity:    "major",
+			Location:    codeQualityLocation{Path: ".", Lines: codeQualityLines{Begin: 1}},
+		}
+		if f.Severity == SeverityWarn {
+			issue.Severity = "minor"
+		}
+		if len(f.Locations) > 0 {
+			l := f.Locations[0]
+			issue.Location.Path = filepath.ToSlash(l.File)
+			issue.Location.Lines.Begin = max(l.Line, 1)
+		}
+		issues = append(issues, issue)
+	}
+	return json.MarshalIndent(issues, "", "  ")
+}
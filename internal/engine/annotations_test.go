@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRenderGitHubAnnotationsEscapesCommands(t *testing.T) {
+	report := CheckReport{Findings: []Finding{
+		{Rule: RuleCycle, Code: CodeCycle, Severity: SeverityError, Message: "cycle: a -> b -> a",
+			Locations: []Location{{File: "src/a,b.go", Line: 3, Node: "a"}}, Suggestion: "remove 100%"},
+		{Rule: RuleRedundantDep, Code: CodeRedundantDep, Severity: SeverityWarn, Message: "redundant"},
+	}}
+	out := RenderGitHubAnnotations(report)
+	want := "::error file=src/a%2Cb.go,line=3,title=CG011 cycle::cycle: a -> b -> a%0Aremove 100%25\n" +
+		"::warning title=CG017 redundant-dep::redundant\n"
+	if out != want {
+		t.Fatalf("unexpected annotations:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestRenderGitLabCodeQualityFingerprints(t *testing.T) {
+	finding := func(line int) Finding {
+		return Finding{Rule: RuleIsolated, Code: CodeIsolated, Severity: SeverityError, Message: `isolated node "a"`,
+			Nodes: []string{"a"}, Locations: []Location{{File: "a.go", Line: line, Node: "a"}}}
+	}
+	render := func(findings ...Finding) []map[string]any {
+		data, err := RenderGitLabCodeQuality(CheckReport{Findings: findings})
+		if err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		var issues []map[string]any
+		if err := json.Unmarshal(data, &issues); err != nil {
+			t.Fatalf("decode: %v\n%s", err, data)
+		}
+		return issues
+	}
+
+	issues := render(finding(1), finding(1))
+	if len(issues) != 2 || issues[0]["fingerprint"] == issues[1]["fingerprint"] {
+		t.Fatalf("expected distinct fingerprints, got %v", issues)
+	}
+	if issues[0]["severity"] != "major" || issues[0]["check_name"] != CodeIsolated {
+		t.Fatalf("unexpected issue: %v", issues[0])
+	}
+	if moved := render(finding(9)); moved[0]["fingerprint"] != issues[0]["fingerprint"] {
+		t.Fatalf("fingerprint changed with the line: %v vs %v", moved[0], issues[0])
+	}
+	if loc := issues[0]["location"].(map[string]any); loc["path"] != "a.go" || loc["lines"].(map[string]any)["begin"] != float64(1) {
+		t.Fatalf("unexpected location: %v", loc)
+	}
+	if empty := render(); empty == nil {
+		t.Fatalf("expected an empty array for no findings")
+	}
+}
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefaultBaselineFile is where check --write-baseline records findings when no
+// path is given.
+const DefaultBaselineFile = ".comment-graph-baseline.json"
+
+// Baseline records known findings so that check only fails on new ones.
+// Entries are keyed by rule, file and node IDs rather than line numbers, so
+// they survive unrelated edits.
+type Baseline struct {
+	Version  int             `json:"version"`
+	Findings []BaselineEntry `json:"findings"`
+}
+
+// BaselineEntry counts the findings that share a rule, primary file and set
+// of nodes. File is slash-separated so baselines are portable between
+// operating systems.
+type BaselineEntry struct {
+	Rule  string   `json:"rule"`
+	File  string   `json:"file,omitempty"`
+	Nodes []string `json:"nodes,omitempty"`
+	Count int      `json:"count"`
+}
+
+type baselineKey struct {
+	rule  string
+	file  string
+	nodes string
+}
+
+func keyOf(f Finding) baselineKey {
+	k := baselineKey{rule: f.Rule}
+	if len(f.Locations) > 0 {
+		k.file = filepath.ToSlash(f.Locations[0].File)
+	}
+	nodes := slices.Clone(f.Nodes)
+	sort.Strings(nodes)
+	k.nodes = strings.Join(nodes, ",")
+	return k
+}
+
+func (k baselineKey) entry(count int) BaselineEntry {
+	e := BaselineEntry{Rule: k.rule, File: k.file, Count: count}
+	if k.nodes != "" {
+		e.Nodes = strings.Split(k.nodes, ",")
+	}
+	return e
+}
+
+// NewBaseline records findings, typically CheckReport.Findings.
+func NewBaseline(findings []Finding) Baseline {
+	counts := make(map[baselineKey]int)
+	for _, f := range findings {
+		counts[keyOf(f)]++
+	}
+	return Baseline{Version: 1, Findings: baselineEntries(counts)}
+}
+
+// counts returns the remaining matches per key; a nil baseline has none.
+func (b *Baseline) counts() map[baselineKey]int {
+	counts := make(map[baselineKey]int)
+	if b == nil {
+		return counts
+	}
+	for _, e := range b.Findings {
+		k := baselineKey{rule: e.Rule, file: filepath.ToSlash(e.File), nodes: strings.Join(e.Nodes, ",")}
+		counts[k] += max(e.Count, 1)
+	}
+	return counts
+}
+
+// baselineEntries returns the non-zero counts sorted by rule, file and nodes.
+func baselineEntries(counts map[baselineKey]int) []BaselineEntry {
+	keys := make([]baselineKey, 0, len(counts))
+	for k, n := range counts {
+		if n > 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].rule != keys[j].rule {
+			return keys[i].rule < keys[j].rule
+		}
+		if keys[i].file != keys[j].file {
+			return keys[i].file < keys[j].file
+		}
+		return keys[i].nodes < keys[j].nodes
+	})
+	entries := make([]BaselineEntry, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries, k.entry(counts[k]))
+	}
+	return entries
+}
+
+// ReadBaselineFile reads a baseline written by WriteBaselineFile.
+func ReadBaselineFile(path string) (Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Baseline{}, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return Baseline{}, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if b.Version != 1 {
+		return Baseline{}, fmt.Errorf("%s: unsupported baseline version %d", filepath.Base(path), b.Version)
+	}
+	return b, nil
+}
+
+// WriteBaselineFile writes b to path as indented JSON.
+func WriteBaselineFile(path string, b Baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func TestBaselineHidesKnownFindingsAcrossLineChanges(t *testing.T) {
+	before := graph.Graph{Nodes: map[string]graph.Node{
+		"old":   {ID: "old", File: "a.go", Line: 3},
+		"fixed": {ID: "fixed", File: "b.go", Line: 1},
+	}}
+	baseline := NewBaseline(ValidateGraph(before, nil).Findings)
+	if len(baseline.Findings) != 2 || baseline.Findings[0].Nodes[0] != "old" {
+		t.Fatalf("unexpected baseline: %+v", baseline)
+	}
+
+	path := filepath.Join(t.TempDir(), DefaultBaselineFile)
+	if err := WriteBaselineFile(path, baseline); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+	read, err := ReadBaselineFile(path)
+	if err != nil {
+		t.Fatalf("read baseline: %v", err)
+	}
+
+	after := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"old":   {ID: "old", File: "a.go", Line: 10},
+			"new":   {ID: "new", File: "c.go", Line: 1},
+			"fixed": {ID: "fixed", File: "b.go", Line: 1},
+			"dep":   {ID: "dep", File: "b.go", Line: 4},
+		},
+		Edges: []graph.Edge{{From: "dep", To: "fixed", Type: "blocks"}},
+	}
+	cfg := DefaultConfig()
+	cfg.Baseline = &read
+	report := ValidateGraphWithConfig(after, nil, cfg)
+
+	if len(report.Isolated) != 1 || report.Isolated[0] != "new" {
+		t.Fatalf("expected only the new isolated node to fail, got %v", report.Isolated)
+	}
+	if report.Baselined != 1 {
+		t.Fatalf("expected one baselined finding, got %d", report.Baselined)
+	}
+	if len(report.Fixed) != 1 || report.Fixed[0].Nodes[0] != "fixed" || report.Fixed[0].File != "b.go" {
+		t.Fatalf("unexpected fixed entries: %+v", report.Fixed)
+	}
+}
+
+func TestBaselineCountsRepeatedFindings(t *testing.T) {
+	errs := []ScanError{
+		{File: "a.go", Line: 1, Msg: "metadata without @cgraph-id", Rule: RuleInvalidMetadata},
+		{File: "a.go", Line: 5, Msg: "metadata without @cgraph-id", Rule: RuleInvalidMetadata},
+	}
+	baseline := NewBaseline(ValidateGraph(graph.Graph{}, errs[:1]).Findings)
+
+	cfg := DefaultConfig()
+	cfg.Baseline = &baseline
+	report := ValidateGraphWithConfig(graph.Graph{}, errs, cfg)
+
+	if len(report.ScanErrors) != 1 || report.Baselined != 1 || len(report.Fixed) != 0 {
+		t.Fatalf("expected one new scan error, got %+v", report)
+	}
+}
+
+func TestBaselineUsesSlashSeparatedFiles(t *testing.T) {
+	g := graph.Graph{Nodes: map[string]graph.Node{
+		"lonely": {ID: "lonely", File: filepath.Join("web", "app", "a.go"), Line: 1},
+	}}
+	baseline := NewBaseline(ValidateGraph(g, nil).Findings)
+	if len(baseline.Findings) != 1 || baseline.Findings[0].File != "web/app/a.go" {
+		t.Fatalf("expected a slash-separated file, got %+v", baseline.Findings)
+	}
+
+	written := Baseline{Version: 1, Findings: []BaselineEntry{{Rule: RuleIsolated, File: "web/app/a.go", Nodes: []string{"lonely"}, Count: 1}}}
+	cfg := DefaultConfig()
+	cfg.Baseline = &written
+	report := ValidateGraphWithConfig(g, nil, cfg)
+	if len(report.Isolated) != 0 || report.Baselined != 1 {
+		t.Fatalf("expected the slash-separated entry to match, got %+v", report)
+	}
+}
@@ -2,158 +2,259 @@ package engine
 
 import (
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/kuri-sun/comment-graph/internal/graph"
 )
 
 // CheckReport contains the results of validation.
+// UndefinedEdges, Cycles, Isolated and ScanErrors only hold findings whose
+// rule has error severity; Findings lists every finding that is not off.
 type CheckReport struct {
 	UndefinedEdges []graph.Edge `json:"undefinedEdges"`
 	Cycles         [][]string   `json:"cycles"`
 	Isolated       []string     `json:"isolated"`
 	ScanErrors     []ScanError  `json:"scanErrors"`
+	ScanWarnings   []ScanError  `json:"scanWarnings"`
 	Mismatch       bool         `json:"mismatch"`
+	Findings       []Finding    `json:"findings"`
+	// Baselined counts findings hidden because the baseline records them.
+	Baselined int `json:"baselined,omitempty"`
+	// Fixed lists baseline entries that no longer match any finding.
+	Fixed []BaselineEntry `json:"fixed,omitempty"`
 }
 
-// ValidateGraph runs dependency checks on a scanned graph.
+// ValidateGraph runs dependency checks on a scanned graph using the default
+// rule severities.
 func ValidateGraph(g graph.Graph, scanErrs []ScanError) CheckReport {
-	undefined := findUndefined(g)
-	cycles := findCycles(g)
-	isolated := findIsolated(g)
-
-	sort.Strings(isolated)
+	return ValidateGraphWithConfig(g, scanErrs, DefaultConfig())
+}
 
-	return CheckReport{
-		UndefinedEdges: undefined,
-		Cycles:         cycles,
-		Isolated:       isolated,
-		ScanErrors:     scanErrs,
+// ValidateGraphWithConfig runs dependency checks on a scanned graph, applying
+// the rule severities from cfg. Scan errors whose rule is downgraded to a
+// warning are moved to ScanWarnings. Findings involving a node that lists the
+// rule in @cgraph-ignore are dropped; ignores that drop nothing are reported
+// as unused-suppression. Findings recorded in cfg.Baseline are counted in
+// Baselined instead of being reported, and unmatched entries go to Fixed.
+func ValidateGraphWithConfig(g graph.Graph, scanErrs []ScanError, cfg Config) CheckReport {
+	var report CheckReport
+	used := make(map[suppression]bool)
+	known := cfg.Baseline.counts()
+	add := func(rule string, f Finding) (Severity, bool) {
+		if s, ok := suppressedBy(g, rule, f.Nodes); ok {
+			used[s] = true
+			return SeverityOff, false
+		}
+		severity := cfg.Severity(rule)
+		if severity == SeverityOff {
+			return severity, false
+		}
+		f.Rule = rule
+		if f.Code == "" {
+			f.Code = ruleCodes[rule]
+		}
+		f.Severity = severity
+		if k := keyOf(f); known[k] > 0 {
+			known[k]--
+			report.Baselined++
+			return SeverityOff, false
+		}
+		report.Findings = append(report.Findings, f)
+		return severity, severity == SeverityError
 	}
-}
 
-func findUndefined(g graph.Graph) []graph.Edge {
-	var out []graph.Edge
-	for _, e := range g.Edges {
-		if _, ok := g.Nodes[e.From]; !ok {
-			out = append(out, e)
-			continue
+	for _, e := range scanErrs {
+		rule := e.Rule
+		if rule == "" {
+			rule = RuleInvalidMetadata
 		}
-		if _, ok := g.Nodes[e.To]; !ok {
-			out = append(out, e)
-			continue
+		severity, failing := add(rule, Finding{Code: e.Code, Message: e.Msg, Locations: []Location{{File: e.File, Line: e.Line}}})
+		switch {
+		case failing:
+			report.ScanErrors = append(report.ScanErrors, e)
+		case severity == SeverityWarn:
+			report.ScanWarnings = append(report.ScanWarnings, e)
 		}
 	}
-	return out
-}
 
-func findIsolated(g graph.Graph) []string {
-	degree := make(map[string]int)
-	for id := range g.Nodes {
-		degree[id] = 0
+	for _, e := range findUndefined(g) {
+		if _, failing := add(RuleUndefinedRef, undefinedFinding(g, e)); failing {
+			report.UndefinedEdges = append(report.UndefinedEdges, e)
+		}
 	}
-	for _, e := range g.Edges {
-		degree[e.From]++
-		degree[e.To]++
+
+	for _, c := range findCycleComponents(g) {
+		if _, failing := add(RuleCycle, cycleFinding(g, c)); failing {
+			report.Cycles = append(report.Cycles, c.Cycles...)
+		}
 	}
-	var isolated []string
-	for id, d := range degree {
-		if d == 0 {
-			isolated = append(isolated, id)
+
+	isolated := findIsolated(g)
+	sort.Strings(isolated)
+	for _, id := range isolated {
+		f := Finding{Message: fmt.Sprintf("isolated node %q", id), Nodes: []string{id}, Locations: nodeLocations(g, []string{id})}
+		if _, failing := add(RuleIsolated, f); failing {
+			report.Isolated = append(report.Isolated, id)
 		}
 	}
-	return isolated
-}
 
-func findCycles(g graph.Graph) [][]string {
-	adj := make(map[string][]string)
-	for _, e := range g.Edges {
-		adj[e.From] = append(adj[e.From], e.To)
+	for _, f := range findPolicyViolations(g, cfg.Policies) {
+		add(RulePolicy, f)
 	}
 
-	var cycles [][]string
-	visited := make(map[string]bool)
-	var stack []string
-	onStack := make(map[string]bool)
+	depth, fanIn, fanOut := findLimitViolations(g, cfg.Limits)
+	for _, f := range depth {
+		add(RuleMaxDepth, f)
+	}
+	for _, f := range fanIn {
+		add(RuleMaxFanIn, f)
+	}
+	for _, f := range fanOut {
+		add(RuleMaxFanOut, f)
+	}
 
-	var dfs func(string)
-	dfs = func(node string) {
-		visited[node] = true
-		onStack[node] = true
-		stack = append(stack, node)
+	if cfg.Severity(RuleRedundantDep) != SeverityOff {
+		for _, f := range findRedundantDeps(g) {
+			add(RuleRedundantDep, f)
+		}
+	}
 
-		for _, next := range adj[node] {
-			if !visited[next] {
-				dfs(next)
-			} else if onStack[next] {
-				cycle := extractCycle(stack, next)
-				if len(cycle) > 0 {
-					cycles = append(cycles, cycle)
-				}
-			}
+	for _, s := range suppressions(g) {
+		if used[s] {
+			continue
 		}
+		add(RuleUnusedSuppression, Finding{
+			Message:   fmt.Sprintf("@cgraph-ignore %s on %q does not suppress anything", s.rule, s.node),
+			Nodes:     []string{s.node},
+			Locations: nodeLocations(g, []string{s.node}),
+		})
+	}
 
-		onStack[node] = false
-		stack = stack[:len(stack)-1]
+	if cfg.Baseline != nil {
+		report.Fixed = baselineEntries(known)
 	}
+	return report
+}
 
-	for id := range g.Nodes {
-		if !visited[id] {
-			dfs(id)
+// RuleErrors returns the error findings of rules that have no dedicated
+// CheckReport field (see Rule.Dedicated), such as policy violations.
+func (r CheckReport) RuleErrors() []Finding {
+	var out []Finding
+	for _, f := range r.Findings {
+		if f.Severity != SeverityError {
+			continue
+		}
+		if rule, ok := LookupRule(f.Rule); ok && rule.Dedicated {
+			continue
 		}
+		out = append(out, f)
 	}
+	return out
+}
 
-	return dedupeCycles(cycles)
+// suppression is a rule silenced on a node with @cgraph-ignore.
+type suppression struct {
+	node string
+	rule string
 }
 
-func extractCycle(stack []string, target string) []string {
-	for i := len(stack) - 1; i >= 0; i-- {
-		if stack[i] == target {
-			cycle := append([]string{}, stack[i:]...)
-			cycle = append(cycle, target)
-			return cycle
+// suppressedBy returns the first node among ids that ignores rule.
+func suppressedBy(g graph.Graph, rule string, ids []string) (suppression, bool) {
+	for _, id := range ids {
+		if slices.Contains(g.Nodes[id].Ignore, rule) {
+			return suppression{node: id, rule: rule}, true
 		}
 	}
-	return nil
+	return suppression{}, false
 }
 
-func dedupeCycles(cycles [][]string) [][]string {
-	type key struct {
-		start string
-		path  string
+// suppressions lists every @cgraph-ignore entry in node ID order.
+func suppressions(g graph.Graph) []suppression {
+	var out []suppression
+	for _, id := range sortedNodeIDs(g) {
+		for _, rule := range g.Nodes[id].Ignore {
+			out = append(out, suppression{node: id, rule: rule})
+		}
 	}
-	seen := make(map[key]bool)
-	var out [][]string
-	for _, cycle := range cycles {
-		if len(cycle) == 0 {
+	return out
+}
+
+// cycleFinding reports a cyclic component with the edge to remove first.
+func cycleFinding(g graph.Graph, c cycleComponent) Finding {
+	f := Finding{Nodes: c.Nodes, Locations: nodeLocations(g, c.Nodes)}
+	switch {
+	case len(c.Cycles) == 1 && !c.Truncated:
+		f.Message = "cycle: " + strings.Join(c.Cycles[0], " -> ")
+	case c.Truncated:
+		f.Message = fmt.Sprintf("at least %d cycles among %s", len(c.Cycles), strings.Join(c.Nodes, ", "))
+	default:
+		f.Message = fmt.Sprintf("%d cycles among %s", len(c.Cycles), strings.Join(c.Nodes, ", "))
+	}
+	if c.Break.From != "" {
+		f.Suggestion = fmt.Sprintf("remove %q from the @cgraph-deps of %q", c.Break.From, c.Break.To)
+	}
+	return f
+}
+
+func undefinedFinding(g graph.Graph, e graph.Edge) Finding {
+	_, fromOK := g.Nodes[e.From]
+	_, toOK := g.Nodes[e.To]
+	f := Finding{Nodes: []string{e.From, e.To}}
+	switch {
+	case !fromOK && toOK:
+		f.Message = fmt.Sprintf("%q depends on undefined node %q", e.To, e.From)
+	case fromOK && !toOK:
+		f.Message = fmt.Sprintf("undefined node %q depends on %q", e.To, e.From)
+	default:
+		f.Message = fmt.Sprintf("undefined nodes %q and %q", e.From, e.To)
+	}
+	// the @cgraph-deps line lives with the dependant, so list it first
+	f.Locations = nodeLocations(g, []string{e.To, e.From})
+	return f
+}
+
+// nodeLocations returns the locations of the defined nodes among ids.
+func nodeLocations(g graph.Graph, ids []string) []Location {
+	var locs []Location
+	for _, id := range ids {
+		if n, ok := g.Nodes[id]; ok {
+			locs = append(locs, Location{File: n.File, Line: n.Line, Node: id})
+		}
+	}
+	return locs
+}
+
+func findUndefined(g graph.Graph) []graph.Edge {
+	var out []graph.Edge
+	for _, e := range g.Edges {
+		if _, ok := g.Nodes[e.From]; !ok {
+			out = append(out, e)
 			continue
 		}
-		normalized := normalizeCycle(cycle)
-		k := key{start: normalized[0], path: fmt.Sprint(normalized)}
-		if seen[k] {
+		if _, ok := g.Nodes[e.To]; !ok {
+			out = append(out, e)
 			continue
 		}
-		seen[k] = true
-		out = append(out, normalized)
 	}
 	return out
 }
 
-func normalizeCycle(c []string) []string {
-	if len(c) == 0 {
-		return c
+func findIsolated(g graph.Graph) []string {
+	degree := make(map[string]int)
+	for id := range g.Nodes {
+		degree[id] = 0
 	}
-	// find lexicographically smallest starting point to make comparison stable
-	minIdx := 0
-	for i := 1; i < len(c)-1; i++ { // last element duplicates start
-		if c[i] < c[minIdx] {
-			minIdx = i
-		}
+	for _, e := range g.Edges {
+		degree[e.From]++
+		degree[e.To]++
 	}
-	var out []string
-	for i := 0; i < len(c)-1; i++ {
-		out = append(out, c[(minIdx+i)%(len(c)-1)])
+	var isolated []string
+	for id, d := range degree {
+		if d == 0 {
+			isolated = append(isolated, id)
+		}
 	}
-	out = append(out, out[0])
-	return out
+	return isolated
 }
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/kuri-sun/comment-graph/internal/graph"
@@ -184,3 +185,97 @@ func TestValidateGraphReportsUndefinedEdgeWithBothMissing(t *testing.T) {
 		t.Fatalf("unexpected undefined edge: %+v", e)
 	}
 }
+
+func TestValidateGraphWithConfigAppliesSeverities(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a":    {ID: "a", File: "a.go", Line: 1},
+			"b":    {ID: "b", File: "b.go", Line: 1},
+			"solo": {ID: "solo", File: "solo.go", Line: 3},
+		},
+		Edges: []graph.Edge{
+			{From: "a", To: "b", Type: "blocks"},
+			{From: "b", To: "a", Type: "blocks"},
+		},
+	}
+	cfg := DefaultConfig()
+	cfg.Rules[RuleIsolated] = SeverityWarn
+	cfg.Rules[RuleCycle] = SeverityOff
+
+	report := ValidateGraphWithConfig(g, nil, cfg)
+
+	if len(report.Cycles) != 0 || len(report.Isolated) != 0 {
+		t.Fatalf("expected no failing cycles or isolated nodes, got %+v", report)
+	}
+	if len(report.Findings) != 1 {
+		t.Fatalf("expected only the isolated warning, got %+v", report.Findings)
+	}
+	f := report.Findings[0]
+	if f.Rule != RuleIsolated || f.Severity != SeverityWarn || len(f.Locations) != 1 || f.Locations[0].File != "solo.go" {
+		t.Fatalf("unexpected finding: %+v", f)
+	}
+}
+
+func TestValidateGraphSplitsScanFindingsBySeverity(t *testing.T) {
+	scanErrs := []ScanError{
+		{File: "a.go", Line: 1, Msg: "metadata without @cgraph-id", Rule: RuleInvalidMetadata},
+		{File: "big.sql", Msg: "skipped file", Rule: RuleSkippedFile},
+	}
+
+	report := ValidateGraph(graph.Graph{}, scanErrs)
+
+	if len(report.ScanErrors) != 1 || report.ScanErrors[0].File != "a.go" {
+		t.Fatalf("unexpected scan errors: %+v", report.ScanErrors)
+	}
+	if len(report.ScanWarnings) != 1 || report.ScanWarnings[0].File != "big.sql" {
+		t.Fatalf("unexpected scan warnings: %+v", report.ScanWarnings)
+	}
+	if len(report.Findings) != 2 || report.Findings[1].Severity != SeverityWarn {
+		t.Fatalf("unexpected findings: %+v", report.Findings)
+	}
+}
+
+func TestValidateGraphHonoursInlineSuppressions(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a":     {ID: "a", File: "a.go", Line: 1, Ignore: []string{RuleCycle}},
+			"b":     {ID: "b", File: "b.go", Line: 1},
+			"notes": {ID: "notes", File: "notes.md", Line: 2, Ignore: []string{RuleIsolated}},
+			"stale": {ID: "stale", File: "stale.go", Line: 5, Ignore: []string{RuleUndefinedRef}},
+			"c":     {ID: "c", File: "c.go", Line: 1},
+		},
+		Edges: []graph.Edge{
+			{From: "a", To: "b", Type: "blocks"},
+			{From: "b", To: "a", Type: "blocks"},
+			{From: "c", To: "stale", Type: "blocks"},
+		},
+	}
+
+	report := ValidateGraph(g, nil)
+
+	if len(report.Cycles) != 0 || len(report.Isolated) != 0 || len(report.UndefinedEdges) != 0 {
+		t.Fatalf("expected suppressed findings to be dropped, got %+v", report)
+	}
+	if len(report.Findings) != 1 {
+		t.Fatalf("expected a single unused suppression, got %+v", report.Findings)
+	}
+	f := report.Findings[0]
+	if f.Rule != RuleUnusedSuppression || f.Severity != SeverityWarn || len(f.Nodes) != 1 || f.Nodes[0] != "stale" {
+		t.Fatalf("unexpected finding: %+v", f)
+	}
+}
+
+func TestRuleErrorsSkipsDedicatedRules(t *testing.T) {
+	var report CheckReport
+	for _, r := range Rules {
+		report.Findings = append(report.Findings, Finding{Rule: r.ID, Severity: SeverityError})
+	}
+	var got []string
+	for _, f := range report.RuleErrors() {
+		got = append(got, f.Rule)
+	}
+	want := []string{RulePolicy, RuleMaxDepth, RuleMaxFanIn, RuleMaxFanOut, RuleRedundantDep, RuleUnusedSuppression}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected rule errors for %v, got %v", want, got)
+	}
+}
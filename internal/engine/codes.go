@@ -0,0 +1,209 @@
+package engine
+
+import "strings"
+
+// Stable codes identifying each kind of scan error and finding. Codes are
+// never reused or renumbered, so tools can match on them instead of messages.
+const (
+	CodeMissingID         = "CG001"
+	CodeEmptyID           = "CG002"
+	CodeInvalidID         = "CG003"
+	CodeIDList            = "CG004"
+	CodeUnknownMetadata   = "CG005"
+	CodeInvalidIgnore     = "CG006"
+	CodeDuplicateID       = "CG007"
+	CodeSkippedFile       = "CG008"
+	CodeSkippedSymlink    = "CG009"
+	CodeUndefinedRef      = "CG010"
+	CodeCycle             = "CG011"
+	CodeIsolated          = "CG012"
+	CodePolicy            = "CG013"
+	CodeMaxDepth          = "CG014"
+	CodeMaxFanIn          = "CG015"
+	CodeMaxFanOut         = "CG016"
+	CodeRedundantDep      = "CG017"
+	CodeUnusedSuppression = "CG018"
+)
+
+// Code documents one error code for `comment-graph explain`.
+type Code struct {
+	ID          string
+	Rule        string
+	Title       string
+	Description string
+	// Example shows source that triggers the code.
+	Example string
+	// Fix describes how to resolve it, with the corrected source if useful.
+	Fix string
+}
+
+// Codes lists every code in numeric order.
+var Codes = []Code{
+	{
+		ID:          CodeMissingID,
+		Rule:        RuleInvalidMetadata,
+		Title:       "metadata without @cgraph-id",
+		Description: "A comment block has @cgraph-deps, @cgraph-label, @cgraph-tags or @cgraph-ignore but no @cgraph-id, so there is no node to attach them to.",
+		Example:     "// @cgraph-deps db-init\nfunc migrate() {}",
+		Fix:         "Add an @cgraph-id to the same comment block:\n\n// @cgraph-id migrate\n// @cgraph-deps db-init\nfunc migrate() {}",
+	},
+	{
+		ID:          CodeEmptyID,
+		Rule:        RuleInvalidMetadata,
+		Title:       "empty @cgraph-id",
+		Description: "@cgraph-id is present but has no value.",
+		Example:     "// @cgraph-id",
+		Fix:         "Give the node an id:\n\n// @cgraph-id cache-warmup",
+	},
+	{
+		ID:          CodeInvalidID,
+		Rule:        RuleInvalidMetadata,
+		Title:       "invalid id",
+		Description: "Ids in @cgraph-id, @cgraph-deps and @cgraph-tags may only use lowercase letters, digits, hyphens and underscores.",
+		Example:     "// @cgraph-id CacheWarmup",
+		Fix:         "Use lowercase letters, digits, hyphens or underscores:\n\n// @cgraph-id cache-warmup",
+	},
+	{
+		ID:          CodeIDList,
+		Rule:        RuleInvalidMetadata,
+		Title:       "ids not comma-separated",
+		Description: "@cgraph-deps and @cgraph-tags take a comma-separated list; ids separated only by spaces are rejected.",
+		Example:     "// @cgraph-deps db-init cache-warmup",
+		Fix:         "Separate the ids with commas:\n\n// @cgraph-deps db-init, cache-warmup",
+	},
+	{
+		ID:          CodeUnknownMetadata,
+		Rule:        RuleInvalidMetadata,
+		Title:       "unknown metadata",
+		Description: "A comment line in a comment-graph block starts with @ but is not a known @cgraph- key, often because of a typo.",
+		Example:     "// @cgraph-id migrate\n// @cgraph-dep db-init",
+		Fix:         "Use one of " + strings.Join(metadataKeys, ", ") + ":\n\n// @cgraph-id migrate\n// @cgraph-deps db-init",
+	},
+	{
+		ID:          CodeInvalidIgnore,
+		Rule:        RuleInvalidMetadata,
+		Title:       "invalid @cgraph-ignore",
+		Description: "@cgraph-ignore must list rules that can be suppressed on a node: " + strings.Join(suppressibleRules, ", ") + ".",
+		Example:     "// @cgraph-id legacy-job\n// @cgraph-ignore duplicate-id",
+		Fix:         "Name a suppressible rule, or remove the line:\n\n// @cgraph-id legacy-job\n// @cgraph-ignore isolated",
+	},
+	{
+		ID:          CodeDuplicateID,
+		Rule:        RuleDuplicateID,
+		Title:       "duplicate id",
+		Description: "The same @cgraph-id is defined more than once. The first definition wins and later ones are reported.",
+		Example:     "// a.go\n// @cgraph-id migrate\n\n// b.go\n// @cgraph-id migrate",
+		Fix:         "Rename one of the nodes and update the @cgraph-deps that refer to it:\n\n// b.go\n// @cgraph-id migrate-data",
+	},
+	{
+		ID:          CodeSkippedFile,
+		Rule:        RuleSkippedFile,
+		Title:       "skipped file",
+		Description: "A file was larger than --max-file-size or had a line longer than --max-line-length, so it was not scanned.",
+		Example:     "comment-graph check --max-file-size 1024   # with a 2 KiB source file",
+		Fix:         "Raise the limit (-1 disables it), or skip the file with --ignore if it holds no metadata.",
+	},
+	{
+		ID:          CodeSkippedSymlink,
+		Rule:        RuleSkippedFile,
+		Title:       "skipped symlink",
+		Description: "With --follow-symlinks, a symlink pointed outside the scanned tree, could not be resolved, or looped back to a directory already being scanned.",
+		Example:     "ln -s .. vendor/parent\ncomment-graph check --follow-symlinks",
+		Fix:         "Remove or retarget the link, or skip it with --ignore.",
+	},
+	{
+		ID:          CodeUndefinedRef,
+		Rule:        RuleUndefinedRef,
+		Title:       "undefined reference",
+		Description: "@cgraph-deps names an id that no comment defines.",
+		Example:     "// @cgraph-id migrate\n// @cgraph-deps db-int",
+		Fix:         "Correct the id, or add the missing node:\n\n// @cgraph-id migrate\n// @cgraph-deps db-init",
+	},
+	{
+		ID:          CodeCycle,
+		Rule:        RuleCycle,
+		Title:       "dependency cycle",
+		Description: "Nodes depend on each other in a loop, so there is no order in which they can be done.",
+		Example:     "// @cgraph-id a\n// @cgraph-deps b\n\n// @cgraph-id b\n// @cgraph-deps a",
+		Fix:         "Remove one dependency in the loop; the hint names the edge shared by the most cycles:\n\n// @cgraph-id b",
+	},
+	{
+		ID:          CodeIsolated,
+		Rule:        RuleIsolated,
+		Title:       "isolated node",
+		Description: "A node has no dependencies and nothing depends on it.",
+		Example:     "// @cgraph-id forgotten-task",
+		Fix:         "Connect it with @cgraph-deps, delete the comment, or mark it as intentional:\n\n// @cgraph-id forgotten-task\n// @cgraph-ignore isolated",
+	},
+	{
+		ID:          CodePolicy,
+		Rule:        RulePolicy,
+		Title:       "policy violation",
+		Description: "A dependency is forbidden by a policy in .comment-graph.yml.",
+		Example:     "policies:\n  - name: web must not depend on migrations\n    nodes: {path: \"web/**\"}\n    deny: {path: \"migrations/**\"}\n\n// web/app.go\n// @cgraph-id render\n// @cgraph-deps migrate",
+		Fix:         "Remove the dependency, or change the policy if it is allowed.",
+	},
+	{
+		ID:          CodeMaxDepth,
+		Rule:        RuleMaxDepth,
+		Title:       "chain too deep",
+		Description: "A chain of dependencies is longer than limits.max-depth.",
+		Example:     "limits:\n  max-depth: 1\n\n// @cgraph-id b\n// @cgraph-deps a\n\n// @cgraph-id c\n// @cgraph-deps b",
+		Fix:         "Shorten the chain, or raise limits.max-depth.",
+	},
+	{
+		ID:          CodeMaxFanIn,
+		Rule:        RuleMaxFanIn,
+		Title:       "too many dependencies",
+		Description: "A node declares more dependencies than limits.max-fan-in.",
+		Example:     "limits:\n  max-fan-in: 2\n\n// @cgraph-id release\n// @cgraph-deps build, test, docs",
+		Fix:         "Split the node, or raise limits.max-fan-in.",
+	},
+	{
+		ID:          CodeMaxFanOut,
+		Rule:        RuleMaxFanOut,
+		Title:       "too many dependants",
+		Description: "More nodes depend on a node than limits.max-fan-out allows.",
+		Example:     "limits:\n  max-fan-out: 2\n\n// @cgraph-deps setup   (on three different nodes)",
+		Fix:         "Introduce an intermediate node, or raise limits.max-fan-out.",
+	},
+	{
+		ID:          CodeRedundantDep,
+		Rule:        RuleRedundantDep,
+		Title:       "redundant dependency",
+		Description: "A dependency is already implied through another dependency.",
+		Example:     "// @cgraph-id b\n// @cgraph-deps a\n\n// @cgraph-id c\n// @cgraph-deps a, b",
+		Fix:         "Drop the implied id, or run `comment-graph check --fix`:\n\n// @cgraph-id c\n// @cgraph-deps b",
+	},
+	{
+		ID:          CodeUnusedSuppression,
+		Rule:        RuleUnusedSuppression,
+		Title:       "unused suppression",
+		Description: "An @cgraph-ignore entry no longer suppresses any finding on its node.",
+		Example:     "// @cgraph-id a\n// @cgraph-deps root\n// @cgraph-ignore isolated",
+		Fix:         "Remove the stale entry:\n\n// @cgraph-id a\n// @cgraph-deps root",
+	},
+}
+
+// LookupCode returns the code with the given ID, ignoring case.
+func LookupCode(id string) (Code, bool) {
+	for _, c := range Codes {
+		if strings.EqualFold(c.ID, id) {
+			return c, true
+		}
+	}
+	return Code{}, false
+}
+
+// ruleCodes maps rules whose findings all share one code.
+var ruleCodes = map[string]string{
+	RuleUndefinedRef:      CodeUndefinedRef,
+	RuleCycle:             CodeCycle,
+	RuleIsolated:          CodeIsolated,
+	RulePolicy:            CodePolicy,
+	RuleMaxDepth:          CodeMaxDepth,
+	RuleMaxFanIn:          CodeMaxFanIn,
+	RuleMaxFanOut:         CodeMaxFanOut,
+	RuleRedundantDep:      CodeRedundantDep,
+	RuleUnusedSuppression: CodeUnusedSuppression,
+}
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCodesAreSequentialAndDocumented(t *testing.T) {
+	for i, c := range Codes {
+		if want := fmt.Sprintf("CG%03d", i+1); c.ID != want {
+			t.Fatalf("code %d is %s, want %s", i, c.ID, want)
+		}
+		if _, ok := LookupRule(c.Rule); !ok {
+			t.Fatalf("%s names unknown rule %q", c.ID, c.Rule)
+		}
+		if c.Title == "" || c.Description == "" || c.Example == "" || c.Fix == "" {
+			t.Fatalf("%s is missing documentation: %+v", c.ID, c)
+		}
+	}
+	for rule, code := range ruleCodes {
+		if c, ok := LookupCode(strings.ToLower(code)); !ok || c.Rule != rule {
+			t.Fatalf("rule %s maps to %s, which documents %q", rule, code, c.Rule)
+		}
+	}
+}
+
+func TestScanErrorsAndFindingsCarryCodes(t *testing.T) {
+	src := strings.Join([]string{
+		"// @cgraph-deps a",
+		"",
+		"// @cgraph-id Bad",
+		"",
+		"// @cgraph-id a",
+		"// @cgraph-dep b",
+		"",
+		"// @cgraph-id b",
+		"// @cgraph-deps a b",
+		"",
+		"// @cgraph-id c",
+		"// @cgraph-deps missing",
+	}, "\n")
+	dir := t.TempDir()
+	writeFile(t, dir, "a.go", src)
+	writeFile(t, dir, "b.go", "// @cgraph-id a\n")
+	g, errs, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	var codes []string
+	for _, e := range errs {
+		codes = append(codes, e.Code)
+	}
+	if got := strings.Join(codes, ","); got != "CG001,CG003,CG005,CG004,CG007" {
+		t.Fatalf("unexpected scan error codes %s: %+v", got, errs)
+	}
+
+	report := ValidateGraph(g, errs)
+	for _, f := range report.Findings {
+		if want, ok := ruleCodes[f.Rule]; f.Code == "" || ok && f.Code != want {
+			t.Fatalf("finding has code %q for rule %s", f.Code, f.Rule)
+		}
+	}
+}
@@ -1,37 +1,114 @@
 package engine
 
 import (
+	"slices"
 	"sort"
 
 	"github.com/kuri-sun/comment-graph/internal/graph"
 )
 
-// GraphsEqual returns true if nodes and edges match, ignoring ordering.
+// GraphsEqual returns true if nodes (including their locations, labels, tags
+// and ignored rules) and edges match, ignoring ordering. Edges are compared as
+// sets, so a repeated edge does not make graphs differ.
 func GraphsEqual(a, b graph.Graph) bool {
-	if len(a.Nodes) != len(b.Nodes) {
-		return false
-	}
-	for id, n := range a.Nodes {
-		bn, ok := b.Nodes[id]
+	return DiffGraphs(a, b).Empty()
+}
+
+// GraphDrift lists how a scanned graph differs from a stored one.
+type GraphDrift struct {
+	AddedNodes   []graph.Node  `json:"addedNodes,omitempty"`
+	RemovedNodes []graph.Node  `json:"removedNodes,omitempty"`
+	MovedNodes   []NodeMove    `json:"movedNodes,omitempty"`
+	LabelChanges []LabelChange `json:"labelChanges,omitempty"`
+	TagChanges   []ListChange  `json:"tagChanges,omitempty"`
+	// IgnoreChanges are changes to a node's @cgraph-ignore rules.
+	IgnoreChanges []ListChange `json:"ignoreChanges,omitempty"`
+	AddedEdges    []graph.Edge `json:"addedEdges,omitempty"`
+	RemovedEdges  []graph.Edge `json:"removedEdges,omitempty"`
+}
+
+// NodeMove is a node whose file or line changed.
+type NodeMove struct {
+	ID   string   `json:"id"`
+	From Location `json:"from"`
+	To   Location `json:"to"`
+}
+
+// LabelChange is a node whose @cgraph-label changed.
+type LabelChange struct {
+	ID   string `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ListChange is a node whose @cgraph-tags or @cgraph-ignore list changed.
+// Lists are compared as sets and reported sorted.
+type ListChange struct {
+	ID   string   `json:"id"`
+	From []string `json:"from"`
+	To   []string `json:"to"`
+}
+
+// Empty reports whether the graphs matched.
+func (d GraphDrift) Empty() bool {
+	return len(d.AddedNodes) == 0 && len(d.RemovedNodes) == 0 && len(d.MovedNodes) == 0 &&
+		len(d.LabelChanges) == 0 && len(d.TagChanges) == 0 && len(d.IgnoreChanges) == 0 &&
+		len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0
+}
+
+// DiffGraphs compares the scanned graph against the stored one, listing each
+// kind of change in ID (or edge) order.
+func DiffGraphs(stored, scanned graph.Graph) GraphDrift {
+	var d GraphDrift
+	for _, id := range sortedNodeIDs(scanned) {
+		n := scanned.Nodes[id]
+		old, ok := stored.Nodes[id]
 		if !ok {
-			return false
+			d.AddedNodes = append(d.AddedNodes, n)
+			continue
 		}
-		if n.File != bn.File || n.Line != bn.Line {
-			return false
+		if old.File != n.File || old.Line != n.Line {
+			d.MovedNodes = append(d.MovedNodes, NodeMove{
+				ID:   id,
+				From: Location{File: old.File, Line: old.Line},
+				To:   Location{File: n.File, Line: n.Line},
+			})
+		}
+		if old.Label != n.Label {
+			d.LabelChanges = append(d.LabelChanges, LabelChange{ID: id, From: old.Label, To: n.Label})
+		}
+		if from, to := sortedSet(old.Tags), sortedSet(n.Tags); !slices.Equal(from, to) {
+			d.TagChanges = append(d.TagChanges, ListChange{ID: id, From: from, To: to})
+		}
+		if from, to := sortedSet(old.Ignore), sortedSet(n.Ignore); !slices.Equal(from, to) {
+			d.IgnoreChanges = append(d.IgnoreChanges, ListChange{ID: id, From: from, To: to})
+		}
+	}
+	for _, id := range sortedNodeIDs(stored) {
+		if _, ok := scanned.Nodes[id]; !ok {
+			d.RemovedNodes = append(d.RemovedNodes, stored.Nodes[id])
 		}
 	}
 
-	ae := normalizeEdges(a.Edges)
-	be := normalizeEdges(b.Edges)
-	if len(ae) != len(be) {
-		return false
+	oldEdges := make(map[graph.Edge]bool)
+	for _, e := range stored.Edges {
+		oldEdges[e] = true
+	}
+	newEdges := make(map[graph.Edge]bool)
+	for _, e := range normalizeEdges(scanned.Edges) {
+		if !oldEdges[e] && !newEdges[e] {
+			d.AddedEdges = append(d.AddedEdges, e)
+		}
+		newEdges[e] = true
 	}
-	for i := range ae {
-		if ae[i] != be[i] {
-			return false
+	removed := make(map[graph.Edge]bool)
+	for _, e := range normalizeEdges(stored.Edges) {
+		if !newEdges[e] && !removed[e] {
+			d.RemovedEdges = append(d.RemovedEdges, e)
+			removed[e] = true
 		}
 	}
-	return true
+	return d
 }
 
 func normalizeEdges(edges []graph.Edge) []graph.Edge {
@@ -47,3 +124,20 @@ func normalizeEdges(edges []graph.Edge) []graph.Edge {
 	})
 	return out
 }
+
+// sortedSet returns the distinct items of list, sorted.
+func sortedSet(list []string) []string {
+	out := slices.Clone(list)
+	sort.Strings(out)
+	return slices.Compact(out)
+}
+
+// sortedNodeIDs returns the IDs of g's nodes in lexical order.
+func sortedNodeIDs(g graph.Graph) []string {
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func TestDiffGraphsListsEachKindOfDrift(t *testing.T) {
+	stored := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a":    {ID: "a", File: "a.go", Line: 1, Label: "Old"},
+			"b":    {ID: "b", File: "b.go", Line: 2},
+			"gone": {ID: "gone", File: "gone.go", Line: 1},
+		},
+		Edges: []graph.Edge{
+			{From: "a", To: "b", Type: "blocks"},
+			{From: "gone", To: "b", Type: "blocks"},
+		},
+	}
+	scanned := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a":   {ID: "a", File: "a.go", Line: 1, Label: "New"},
+			"b":   {ID: "b", File: "lib/b.go", Line: 5},
+			"new": {ID: "new", File: "new.go", Line: 1},
+		},
+		Edges: []graph.Edge{
+			{From: "a", To: "b", Type: "blocks"},
+			{From: "new", To: "b", Type: "blocks"},
+		},
+	}
+
+	d := DiffGraphs(stored, scanned)
+	if len(d.AddedNodes) != 1 || d.AddedNodes[0].ID != "new" {
+		t.Fatalf("unexpected added nodes: %+v", d.AddedNodes)
+	}
+	if len(d.RemovedNodes) != 1 || d.RemovedNodes[0].ID != "gone" {
+		t.Fatalf("unexpected removed nodes: %+v", d.RemovedNodes)
+	}
+	if len(d.MovedNodes) != 1 || d.MovedNodes[0].From.File != "b.go" || d.MovedNodes[0].To.Line != 5 {
+		t.Fatalf("unexpected moves: %+v", d.MovedNodes)
+	}
+	if len(d.LabelChanges) != 1 || d.LabelChanges[0].From != "Old" || d.LabelChanges[0].To != "New" {
+		t.Fatalf("unexpected label changes: %+v", d.LabelChanges)
+	}
+	if len(d.AddedEdges) != 1 || d.AddedEdges[0].From != "new" || len(d.RemovedEdges) != 1 || d.RemovedEdges[0].From != "gone" {
+		t.Fatalf("unexpected edge changes: +%+v -%+v", d.AddedEdges, d.RemovedEdges)
+	}
+	if d.Empty() || !DiffGraphs(scanned, scanned).Empty() {
+		t.Fatalf("Empty() disagrees with the diff")
+	}
+}
+
+func TestGraphsEqualComparesLabels(t *testing.T) {
+	a := graph.Graph{Nodes: map[string]graph.Node{"a": {ID: "a", File: "a.go", Line: 1, Label: "A"}}}
+	b := graph.Graph{Nodes: map[string]graph.Node{"a": {ID: "a", File: "a.go", Line: 1, Label: "B"}}}
+	if GraphsEqual(a, b) {
+		t.Fatalf("expected label change to make graphs differ")
+	}
+}
+
+func TestDiffGraphsComparesTagsAndIgnoreAsSets(t *testing.T) {
+	stored := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a": {ID: "a", File: "a.go", Line: 1, Tags: []string{"api", "db"}, Ignore: []string{"isolated"}},
+			"b": {ID: "b", File: "b.go", Line: 1, Tags: []string{"db", "api"}},
+		},
+		Edges: []graph.Edge{{From: "a", To: "b", Type: "blocks"}},
+	}
+	scanned := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a": {ID: "a", File: "a.go", Line: 1, Tags: []string{"api"}},
+			"b": {ID: "b", File: "b.go", Line: 1, Tags: []string{"api", "db", "api"}},
+		},
+		Edges: []graph.Edge{{From: "a", To: "b", Type: "blocks"}, {From: "a", To: "b", Type: "blocks"}},
+	}
+
+	d := DiffGraphs(stored, scanned)
+	if len(d.TagChanges) != 1 || d.TagChanges[0].ID != "a" || len(d.TagChanges[0].From) != 2 || len(d.TagChanges[0].To) != 1 {
+		t.Fatalf("unexpected tag changes: %+v", d.TagChanges)
+	}
+	if len(d.IgnoreChanges) != 1 || d.IgnoreChanges[0].ID != "a" || len(d.IgnoreChanges[0].To) != 0 {
+		t.Fatalf("unexpected ignore changes: %+v", d.IgnoreChanges)
+	}
+	if len(d.AddedEdges) != 0 || len(d.RemovedEdges) != 0 {
+		t.Fatalf("a repeated edge should not be drift: +%+v -%+v", d.AddedEdges, d.RemovedEdges)
+	}
+}
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ConfigFile is the name of the optional configuration file in the repository root.
+const ConfigFile = ".comment-graph.yml"
+
+// Config holds the settings read from .comment-graph.yml.
+type Config struct {
+	// Rules overrides the default severity of rules by ID.
+	Rules map[string]Severity
+	// Policies restrict which nodes may depend on which.
+	Policies []Policy
+	// Limits bound chain depth and per-node fan-in and fan-out.
+	Limits Limits
+	// Baseline, if set, hides the findings it records. It is not read from
+	// the file; check sets it from --baseline.
+	Baseline *Baseline
+}
+
+// DefaultConfig returns the configuration used when no file is present.
+func DefaultConfig() Config {
+	return Config{Rules: map[string]Severity{}}
+}
+
+// Severity returns the configured severity of rule, falling back to the
+// rule's default.
+func (c Config) Severity(rule string) Severity {
+	if s, ok := c.Rules[rule]; ok {
+		return s
+	}
+	if r, ok := LookupRule(rule); ok {
+		return r.Severity
+	}
+	return SeverityError
+}
+
+// LoadConfig reads .comment-graph.yml from root, returning DefaultConfig if
+// the file does not exist.
+func LoadConfig(root string) (Config, error) {
+	cfg, err := ReadConfigFile(filepath.Join(root, ConfigFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return DefaultConfig(), nil
+	}
+	return cfg, err
+}
+
+// ReadConfigFile parses a configuration file from path.
+func ReadConfigFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	return parseConfig(filepath.Base(path), data)
+}
+
+func parseConfig(name string, data []byte) (Config, error) {
+	doc, err := parseYAML(name, data)
+	if err != nil {
+		return Config{}, err
+	}
+	if doc.kind != yamlMap {
+		return Config{}, fmt.Errorf("%s:%d: expected a mapping, got %s", name, doc.line, doc.kindName())
+	}
+
+	cfg := DefaultConfig()
+	for _, key := range doc.keys {
+		v := doc.fields[key]
+		switch key {
+		case "rules":
+			if err := parseRuleSeverities(name, v, cfg.Rules); err != nil {
+				return Config{}, err
+			}
+		case "limits":
+			if cfg.Limits, err = parseLimits(name, v); err != nil {
+				return Config{}, err
+			}
+		case "policies":
+			if cfg.Policies, err = parsePolicies(name, v); err != nil {
+				return Config{}, err
+			}
+		default:
+			return Config{}, fmt.Errorf("%s:%d: unknown setting %q", name, v.line, key)
+		}
+	}
+	return cfg, nil
+}
+
+func parseRuleSeverities(name string, v *yamlValue, out map[string]Severity) error {
+	if v.kind != yamlMap {
+		return fmt.Errorf("%s:%d: rules must be a mapping of rule id to severity", name, v.line)
+	}
+	for _, id := range v.keys {
+		sv := v.fields[id]
+		if _, ok := LookupRule(id); !ok {
+			return fmt.Errorf("%s:%d: unknown rule %q (known rules: %s)", name, sv.line, id, strings.Join(ruleIDs(), ", "))
+		}
+		if sv.kind != yamlScalar {
+			return fmt.Errorf("%s:%d: severity for %q must be error, warn, or off", name, sv.line, id)
+		}
+		severity, err := ParseSeverity(sv.scalar)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s: %v", name, sv.line, id, err)
+		}
+		out[id] = severity
+	}
+	return nil
+}
+
+func ruleIDs() []string {
+	ids := make([]string, 0, len(Rules))
+	for _, r := range Rules {
+		ids = append(ids, r.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigRuleSeverities(t *testing.T) {
+	cfg, err := parseConfig(ConfigFile, []byte(`# comment-graph settings
+rules:
+  isolated: warn   # intentionally standalone notes
+  cycle: "off"
+  skipped-file: error
+`))
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+	want := map[string]Severity{
+		RuleIsolated:     SeverityWarn,
+		RuleCycle:        SeverityOff,
+		RuleSkippedFile:  SeverityError,
+		RuleUndefinedRef: SeverityError,
+	}
+	for rule, severity := range want {
+		if got := cfg.Severity(rule); got != severity {
+			t.Fatalf("severity for %s = %s, want %s", rule, got, severity)
+		}
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown rule", "rules:\n  nope: warn\n", `2: unknown rule "nope"`},
+		{"bad severity", "rules:\n  cycle: loud\n", `2: cycle: invalid severity "loud"`},
+		{"unknown setting", "color: red\n", `1: unknown setting "color"`},
+		{"bad indentation", "rules:\n  cycle: warn\n    isolated: warn\n", "3: unexpected indentation"},
+		{"rules not a map", "rules: [cycle]\n", "rules must be a mapping"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfig(ConfigFile, []byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigDefaultsWhenMissing(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.Severity(RuleIsolated) != SeverityError || cfg.Severity(RuleSkippedFile) != SeverityWarn {
+		t.Fatalf("unexpected defaults: %+v", cfg)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ConfigFile), []byte("rules:\n  isolated: off\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err = LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.Severity(RuleIsolated) != SeverityOff {
+		t.Fatalf("expected isolated off, got %+v", cfg)
+	}
+}
+
+func TestParseYAMLNestedStructures(t *testing.T) {
+	v, err := parseYAML("test.yml", []byte(`
+top:
+  list:
+  - a
+  - "b # not a comment"
+  flow: [x, 'y''s']
+  inline: {path: "a, b", tags: [x, y]}
+  items:
+    - name: first
+      tags: []
+    - name: second
+`))
+	if err != nil {
+		t.Fatalf("parse yaml: %v", err)
+	}
+	top := v.fields["top"]
+	if list := top.fields["list"]; list.kind != yamlList || len(list.items) != 2 || list.items[1].scalar != "b # not a comment" {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+	if flow := top.fields["flow"]; len(flow.items) != 2 || flow.items[1].scalar != "y's" {
+		t.Fatalf("unexpected flow list: %+v", flow)
+	}
+	if inline := top.fields["inline"]; inline.kind != yamlMap || inline.fields["path"].scalar != "a, b" || len(inline.fields["tags"].items) != 2 {
+		t.Fatalf("unexpected flow mapping: %+v", inline)
+	}
+	items := top.fields["items"]
+	if items.kind != yamlList || len(items.items) != 2 {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+	if items.items[0].fields["name"].scalar != "first" || items.items[0].fields["tags"].kind != yamlList {
+		t.Fatalf("unexpected first item: %+v", items.items[0])
+	}
+	if items.items[1].fields["name"].scalar != "second" {
+		t.Fatalf("unexpected second item: %+v", items.items[1])
+	}
+}
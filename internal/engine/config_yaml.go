@@ -0,0 +1,315 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlValue is a node of the small YAML subset accepted in .comment-graph.yml:
+// block mappings, block sequences, single-line flow collections ([a, b] and
+// {key: value}) and plain or quoted scalars. Anchors, multi-line strings and
+// nested flow mappings are not supported.
+type yamlValue struct {
+	line   int
+	kind   yamlKind
+	scalar string
+	keys   []string
+	fields map[string]*yamlValue
+	items  []*yamlValue
+}
+
+type yamlKind int
+
+const (
+	yamlScalar yamlKind = iota
+	yamlMap
+	yamlList
+)
+
+func (v *yamlValue) kindName() string {
+	switch v.kind {
+	case yamlMap:
+		return "a mapping"
+	case yamlList:
+		return "a list"
+	default:
+		return "a scalar"
+	}
+}
+
+type yamlLine struct {
+	num     int
+	indent  int
+	content string
+}
+
+// parseYAML parses data into a yamlValue; name prefixes error messages.
+func parseYAML(name string, data []byte) (*yamlValue, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		content := strings.TrimSpace(stripYAMLComment(raw))
+		if content == "" || content == "---" {
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		if strings.HasPrefix(raw[indent:], "\t") {
+			return nil, fmt.Errorf("%s:%d: tabs are not allowed for indentation", name, i+1)
+		}
+		lines = append(lines, yamlLine{num: i + 1, indent: indent, content: content})
+	}
+	if len(lines) == 0 {
+		return &yamlValue{kind: yamlMap, fields: map[string]*yamlValue{}}, nil
+	}
+
+	p := &yamlParser{name: name, lines: lines}
+	v, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, p.errorf(p.lines[p.pos], "unexpected indentation")
+	}
+	return v, nil
+}
+
+type yamlParser struct {
+	name  string
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) errorf(l yamlLine, format string, args ...any) error {
+	return fmt.Errorf("%s:%d: %s", p.name, l.num, fmt.Sprintf(format, args...))
+}
+
+// block parses the mapping or sequence whose entries start at indent.
+func (p *yamlParser) block(indent int) (*yamlValue, error) {
+	first := p.lines[p.pos]
+	if first.content == "-" || strings.HasPrefix(first.content, "- ") {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) sequence(indent int) (*yamlValue, error) {
+	v := &yamlValue{line: p.lines[p.pos].num, kind: yamlList}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent != indent || !(l.content == "-" || strings.HasPrefix(l.content, "- ")) {
+			break
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(l.content, "-"))
+		if rest == "" {
+			p.pos++
+			item, err := p.nested(l, indent)
+			if err != nil {
+				return nil, err
+			}
+			v.items = append(v.items, item)
+			continue
+		}
+		if _, _, ok := splitYAMLKey(rest); ok || strings.HasPrefix(rest, "- ") {
+			// "- key: value" starts a mapping indented to the key's column
+			p.lines[p.pos] = yamlLine{num: l.num, indent: indent + len(l.content) - len(rest), content: rest}
+			item, err := p.block(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			v.items = append(v.items, item)
+			continue
+		}
+		p.pos++
+		item, err := p.scalar(l, rest)
+		if err != nil {
+			return nil, err
+		}
+		v.items = append(v.items, item)
+	}
+	return v, nil
+}
+
+func (p *yamlParser) mapping(indent int) (*yamlValue, error) {
+	v := &yamlValue{line: p.lines[p.pos].num, kind: yamlMap, fields: map[string]*yamlValue{}}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, p.errorf(l, "unexpected indentation")
+		}
+		if strings.HasPrefix(l.content, "- ") || l.content == "-" {
+			return nil, p.errorf(l, "unexpected list item")
+		}
+		key, rest, ok := splitYAMLKey(l.content)
+		if !ok {
+			return nil, p.errorf(l, "expected \"key: value\"")
+		}
+		if _, dup := v.fields[key]; dup {
+			return nil, p.errorf(l, "duplicate key %q", key)
+		}
+		p.pos++
+
+		var child *yamlValue
+		var err error
+		if rest == "" {
+			child, err = p.nested(l, indent)
+		} else {
+			child, err = p.scalar(l, rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		v.keys = append(v.keys, key)
+		v.fields[key] = child
+	}
+	return v, nil
+}
+
+// nested parses the block below parent, or an empty scalar if there is none.
+// Sequences may sit at the parent's indentation, as in "key:\n- item".
+func (p *yamlParser) nested(parent yamlLine, indent int) (*yamlValue, error) {
+	if p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		isItem := next.content == "-" || strings.HasPrefix(next.content, "- ")
+		if next.indent > indent || (next.indent == indent && isItem && !strings.HasPrefix(parent.content, "-")) {
+			return p.block(next.indent)
+		}
+	}
+	return &yamlValue{line: parent.num, kind: yamlScalar}, nil
+}
+
+func (p *yamlParser) scalar(l yamlLine, raw string) (*yamlValue, error) {
+	switch {
+	case strings.HasPrefix(raw, "{"):
+		if !strings.HasSuffix(raw, "}") {
+			return nil, p.errorf(l, "unterminated mapping %q", raw)
+		}
+		v := &yamlValue{line: l.num, kind: yamlMap, fields: map[string]*yamlValue{}}
+		for _, part := range splitYAMLFlow(raw[1 : len(raw)-1]) {
+			key, rest, ok := splitYAMLKey(part)
+			if !ok {
+				return nil, p.errorf(l, "expected \"key: value\" in %q", raw)
+			}
+			if _, dup := v.fields[key]; dup {
+				return nil, p.errorf(l, "duplicate key %q", key)
+			}
+			if strings.HasPrefix(rest, "{") {
+				return nil, p.errorf(l, "nested flow mappings are not supported")
+			}
+			child, err := p.scalar(l, rest)
+			if err != nil {
+				return nil, err
+			}
+			v.keys = append(v.keys, key)
+			v.fields[key] = child
+		}
+		return v, nil
+	case strings.HasPrefix(raw, "["):
+		if !strings.HasSuffix(raw, "]") {
+			return nil, p.errorf(l, "unterminated list %q", raw)
+		}
+		v := &yamlValue{line: l.num, kind: yamlList}
+		for _, part := range splitYAMLFlow(raw[1 : len(raw)-1]) {
+			s, err := unquoteYAML(part)
+			if err != nil {
+				return nil, p.errorf(l, "%v", err)
+			}
+			v.items = append(v.items, &yamlValue{line: l.num, kind: yamlScalar, scalar: s})
+		}
+		return v, nil
+	default:
+		s, err := unquoteYAML(raw)
+		if err != nil {
+			return nil, p.errorf(l, "%v", err)
+		}
+		return &yamlValue{line: l.num, kind: yamlScalar, scalar: s}, nil
+	}
+}
+
+// splitYAMLFlow splits the inside of a flow collection on commas that are
+// outside quotes and brackets, trimming each part.
+func splitYAMLFlow(inner string) []string {
+	if strings.TrimSpace(inner) == "" {
+		return nil
+	}
+	var parts []string
+	quote := byte(0)
+	depth, start := 0, 0
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(inner[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(inner[start:]))
+}
+
+// splitYAMLKey splits "key: value" (or "key:") outside of quotes.
+func splitYAMLKey(content string) (string, string, bool) {
+	quote := byte(0)
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ':' && (i+1 == len(content) || content[i+1] == ' '):
+			key, err := unquoteYAML(strings.TrimSpace(content[:i]))
+			if err != nil || key == "" {
+				return "", "", false
+			}
+			return key, strings.TrimSpace(content[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+func unquoteYAML(s string) (string, error) {
+	switch {
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		return strconv.Unquote(s)
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'"):
+		return "", fmt.Errorf("unterminated string %s", s)
+	default:
+		return s, nil
+	}
+}
+
+// stripYAMLComment removes a trailing "# comment" that is outside quotes.
+func stripYAMLComment(line string) string {
+	quote := byte(0)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,208 @@
+package engine
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+const (
+	// maxCyclesPerComponent caps how many elementary cycles are listed for
+	// one strongly connected component.
+	maxCyclesPerComponent = 100
+	// maxCycleSearchSteps bounds the edges followed while enumerating the
+	// cycles of one component, so dense components cannot stall a check.
+	maxCycleSearchSteps = 1_000_000
+)
+
+// cycleComponent is a strongly connected component that contains at least
+// one cycle.
+type cycleComponent struct {
+	// Nodes are the component's members, sorted.
+	Nodes []string
+	// Cycles are its elementary cycles, each starting and ending at its
+	// smallest node, in lexical order.
+	Cycles [][]string
+	// Truncated reports that not every cycle was enumerated.
+	Truncated bool
+	// Break is the edge shared by the most listed cycles; removing it
+	// breaks all of them.
+	Break graph.Edge
+}
+
+// findCycleComponents returns the cyclic strongly connected components of g
+// ordered by their smallest node. It uses an iterative Tarjan pass, so deep
+// graphs cannot overflow the stack.
+func findCycleComponents(g graph.Graph) []cycleComponent {
+	adj, _ := adjacency(g)
+	var out []cycleComponent
+	for _, scc := range stronglyConnected(sortedNodeIDs(g), adj, nil, nil) {
+		if len(scc) == 1 && !slices.Contains(adj[scc[0]], scc[0]) {
+			continue
+		}
+		c := cycleComponent{Nodes: scc}
+		c.Cycles, c.Truncated = elementaryCycles(scc, adj)
+		c.Break = mostSharedEdge(c.Cycles)
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Nodes[0] < out[j].Nodes[0] })
+	return out
+}
+
+// stronglyConnected returns the components of the graph described by adj,
+// each sorted, restricted to member nodes when member is non-nil. steps, if
+// non-nil, is increased by the number of edges followed.
+func stronglyConnected(ids []string, adj map[string][]string, member map[string]bool, steps *int) [][]string {
+	type frame struct {
+		node string
+		next int
+	}
+	index := make(map[string]int, len(ids))
+	low := make(map[string]int, len(ids))
+	onStack := make(map[string]bool)
+	var stack []string
+	var sccs [][]string
+
+	for _, root := range ids {
+		if _, seen := index[root]; seen {
+			continue
+		}
+		index[root], low[root] = len(index), len(index)
+		stack = append(stack, root)
+		onStack[root] = true
+		call := []frame{{node: root}}
+
+		for len(call) > 0 {
+			top := &call[len(call)-1]
+			if top.next < len(adj[top.node]) {
+				w := adj[top.node][top.next]
+				top.next++
+				if steps != nil {
+					*steps++
+				}
+				if member != nil && !member[w] {
+					continue
+				}
+				if _, seen := index[w]; !seen {
+					index[w], low[w] = len(index), len(index)
+					stack = append(stack, w)
+					onStack[w] = true
+					call = append(call, frame{node: w})
+				} else if onStack[w] {
+					low[top.node] = min(low[top.node], index[w])
+				}
+				continue
+			}
+
+			v := top.node
+			call = call[:len(call)-1]
+			if len(call) > 0 {
+				parent := call[len(call)-1].node
+				low[parent] = min(low[parent], low[v])
+			}
+			if low[v] != index[v] {
+				continue
+			}
+			var scc []string
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				scc = append(scc, w)
+				if w == v {
+					break
+				}
+			}
+			sort.Strings(scc)
+			sccs = append(sccs, scc)
+		}
+	}
+	return sccs
+}
+
+// elementaryCycles lists the cycles within scc in lexical order. As in
+// Johnson's algorithm, the cycles through the smallest node are found first;
+// that node is then removed and the search continues in the components that
+// remain, so each cycle is reported once, starting at its smallest node.
+func elementaryCycles(scc []string, adj map[string][]string) ([][]string, bool) {
+	type frame struct {
+		node string
+		next int
+	}
+
+	var cycles [][]string
+	steps := 0
+	truncated := false
+	pending := [][]string{scc}
+	for len(pending) > 0 && !truncated {
+		comp := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		member := make(map[string]bool, len(comp))
+		for _, id := range comp {
+			member[id] = true
+		}
+
+		start := comp[0]
+		path := []string{start}
+		onPath := map[string]bool{start: true}
+		call := []frame{{node: start}}
+		for len(call) > 0 {
+			top := &call[len(call)-1]
+			if top.next == len(adj[top.node]) {
+				onPath[top.node] = false
+				path = path[:len(path)-1]
+				call = call[:len(call)-1]
+				continue
+			}
+			w := adj[top.node][top.next]
+			top.next++
+			if steps++; steps > maxCycleSearchSteps {
+				truncated = true
+				break
+			}
+			switch {
+			case w == start:
+				cycles = append(cycles, append(slices.Clone(path), start))
+				if len(cycles) == maxCyclesPerComponent {
+					truncated = true
+				}
+			case member[w] && !onPath[w]:
+				onPath[w] = true
+				path = append(path, w)
+				call = append(call, frame{node: w})
+			}
+			if truncated {
+				break
+			}
+		}
+
+		delete(member, start)
+		for _, sub := range stronglyConnected(comp[1:], adj, member, &steps) {
+			if len(sub) > 1 || slices.Contains(adj[sub[0]], sub[0]) {
+				pending = append(pending, sub)
+			}
+		}
+	}
+	slices.SortFunc(cycles, slices.Compare)
+	return cycles, truncated
+}
+
+// mostSharedEdge returns the edge that appears in the most cycles, preferring
+// the lexically smallest on ties.
+func mostSharedEdge(cycles [][]string) graph.Edge {
+	counts := make(map[graph.Edge]int)
+	for _, c := range cycles {
+		for i := 0; i+1 < len(c); i++ {
+			counts[graph.Edge{From: c[i], To: c[i+1], Type: "blocks"}]++
+		}
+	}
+	var best graph.Edge
+	bestCount := 0
+	for e, n := range counts {
+		if n > bestCount || (n == bestCount && (e.From < best.From || (e.From == best.From && e.To < best.To))) {
+			best, bestCount = e, n
+		}
+	}
+	return best
+}
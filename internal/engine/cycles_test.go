@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func cycleGraph(ids []string, edges ...[2]string) graph.Graph {
+	g := graph.Graph{Nodes: map[string]graph.Node{}}
+	for _, id := range ids {
+		g.Nodes[id] = graph.Node{ID: id}
+	}
+	for _, e := range edges {
+		g.Edges = append(g.Edges, graph.Edge{From: e[0], To: e[1], Type: "blocks"})
+	}
+	return g
+}
+
+func TestFindCycleComponentsListsEveryElementaryCycle(t *testing.T) {
+	g := cycleGraph([]string{"a", "b", "c", "d"},
+		[2]string{"a", "b"}, [2]string{"b", "a"},
+		[2]string{"b", "c"}, [2]string{"c", "a"},
+		[2]string{"c", "d"},
+	)
+
+	comps := findCycleComponents(g)
+	if len(comps) != 1 {
+		t.Fatalf("expected one component, got %+v", comps)
+	}
+	c := comps[0]
+	if strings.Join(c.Nodes, ",") != "a,b,c" {
+		t.Fatalf("unexpected members: %v", c.Nodes)
+	}
+	var got []string
+	for _, cycle := range c.Cycles {
+		got = append(got, strings.Join(cycle, "->"))
+	}
+	if strings.Join(got, " ") != "a->b->a a->b->c->a" || c.Truncated {
+		t.Fatalf("unexpected cycles: %v (truncated %v)", got, c.Truncated)
+	}
+	if c.Break.From != "a" || c.Break.To != "b" {
+		t.Fatalf("expected a -> b to be suggested, got %+v", c.Break)
+	}
+
+	report := ValidateGraph(g, nil)
+	if len(report.Cycles) != 2 || len(report.Findings) != 1 {
+		t.Fatalf("expected two cycles in one finding, got %+v", report)
+	}
+	if f := report.Findings[0]; f.Message != "2 cycles among a, b, c" || f.Suggestion != `remove "a" from the @cgraph-deps of "b"` {
+		t.Fatalf("unexpected finding: %+v", f)
+	}
+}
+
+func TestFindCycleComponentsCapsEnumeration(t *testing.T) {
+	var ids []string
+	var edges [][2]string
+	for i := 0; i < 8; i++ {
+		ids = append(ids, fmt.Sprintf("n%d", i))
+	}
+	for _, from := range ids {
+		for _, to := range ids {
+			if from != to {
+				edges = append(edges, [2]string{from, to})
+			}
+		}
+	}
+
+	comps := findCycleComponents(cycleGraph(ids, edges...))
+	if len(comps) != 1 || !comps[0].Truncated || len(comps[0].Cycles) != maxCyclesPerComponent {
+		t.Fatalf("expected a truncated component with %d cycles, got %d", maxCyclesPerComponent, len(comps[0].Cycles))
+	}
+}
+
+func TestFindCycleComponentsHandlesDeepGraphs(t *testing.T) {
+	const n = 100_000
+	ids := make([]string, n)
+	edges := make([][2]string, 0, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("n%06d", i)
+		if i > 0 {
+			edges = append(edges, [2]string{ids[i-1], ids[i]})
+		}
+	}
+	edges = append(edges, [2]string{ids[n-1], ids[0]})
+
+	comps := findCycleComponents(cycleGraph(ids, edges...))
+	if len(comps) != 1 || len(comps[0].Nodes) != n || len(comps[0].Cycles) != 1 || comps[0].Truncated {
+		t.Fatalf("expected the whole chain as one cycle, got %d components", len(comps))
+	}
+}
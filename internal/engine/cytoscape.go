@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"encoding/json"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// Cytoscape.js elements JSON, as read by cytoscape({elements}) and imported
+// by Cytoscape desktop.
+type cytoscapeGraph struct {
+	Elements cytoscapeElements `json:"elements"`
+}
+
+type cytoscapeElements struct {
+	Nodes []cytoscapeNode `json:"nodes"`
+	Edges []cytoscapeEdge `json:"edges"`
+}
+
+type cytoscapeNode struct {
+	Data cytoscapeNodeData `json:"data"`
+}
+
+type cytoscapeNodeData struct {
+	ID     string   `json:"id"`
+	Label  string   `json:"label"`
+	File   string   `json:"file,omitempty"`
+	Line   int      `json:"line,omitempty"`
+	Dir    string   `json:"dir,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Ignore []string `json:"ignore,omitempty"`
+	State  string   `json:"state,omitempty"`
+}
+
+type cytoscapeEdge struct {
+	Data cytoscapeEdgeData `json:"data"`
+}
+
+type cytoscapeEdgeData struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type,omitempty"`
+}
+
+// RenderCytoscape renders the graph as Cytoscape.js elements JSON, with the
+// same node and edge attributes as RenderGraphML; tags and ignore stay lists.
+func RenderCytoscape(g graph.Graph, opts DiagramOptions) ([]byte, error) {
+	out := cytoscapeGraph{Elements: cytoscapeElements{
+		Nodes: []cytoscapeNode{},
+		Edges: []cytoscapeEdge{},
+	}}
+	for _, n := range analysisNodes(g, opts) {
+		data := cytoscapeNodeData{
+			ID:     n.ID,
+			Label:  n.Label,
+			File:   n.File,
+			Line:   n.Line,
+			Dir:    n.Dir,
+			Tags:   n.Tags,
+			Ignore: n.Ignore,
+			State:  n.State,
+		}
+		out.Elements.Nodes = append(out.Elements.Nodes, cytoscapeNode{Data: data})
+	}
+	for i, e := range sortEdges(g.Edges) {
+		out.Elements.Edges = append(out.Elements.Edges, cytoscapeEdge{Data: cytoscapeEdgeData{
+			ID:     analysisEdgeID(e, i),
+			Source: e.From,
+			Target: e.To,
+			Type:   e.Type,
+		}})
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
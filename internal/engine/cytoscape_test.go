@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func TestRenderCytoscapeCarriesAttributes(t *testing.T) {
+	g := diagramTestGraph()
+	g.Nodes["lonely"] = graph.Node{ID: "lonely", File: "z.go", Line: 2, Tags: []string{"ops"}}
+
+	data, err := RenderCytoscape(g, DiagramOptions{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	var doc cytoscapeGraph
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("decode: %v\n%s", err, data)
+	}
+	nodes := map[string]cytoscapeNodeData{}
+	for _, n := range doc.Elements.Nodes {
+		nodes[n.Data.ID] = n.Data
+	}
+	if a := nodes["a"]; a.Label != "a" || a.File != "api/a.go" || a.Dir != "api" {
+		t.Fatalf("expected node a with ID text and location, got %+v", a)
+	}
+	if l := nodes["lonely"]; strings.Join(l.Tags, ",") != "ops" || l.Line != 2 || l.State != stateIsolated {
+		t.Fatalf("expected tags, line and isolated state, got %+v", l)
+	}
+	if m := nodes["missing"]; m.State != stateUndefined || m.File != "" {
+		t.Fatalf("expected undefined node without a file, got %+v", m)
+	}
+	if len(doc.Elements.Edges) != 4 || doc.Elements.Edges[3].Data.Source != "missing" || doc.Elements.Edges[3].Data.Type != "blocks" {
+		t.Fatalf("expected typed edges, got %+v", doc.Elements.Edges)
+	}
+	if !strings.Contains(string(data), `"elements": {`) {
+		t.Fatalf("expected elements object, got:\n%s", data)
+	}
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// d2Classes style the node states used by RenderD2.
+var d2Classes = []string{
+	`error: {style: {stroke: "#d33"; stroke-width: 2}}`,
+	`warn: {style: {stroke: "#e90"; stroke-width: 2}}`,
+	`isolated: {style: {fill: "#eee"; stroke: "#999"}}`,
+	`undefined: {style: {stroke: "#d33"; stroke-dash: 4; font-color: "#d33"}}`,
+}
+
+// RenderD2 renders the graph as a D2 diagram: a shape per node inside a
+// container per group, a class for its state and a link to its file and
+// line. Edges on a cycle are red, and undefined nodes and the edges reaching
+// them are red and dashed. Keys share one namespace with each other and with
+// D2 keywords, so nodes are keyed n1, n2, ... in ID order, undefined ones
+// last, and containers g1, g2, ..., each labelled with its ID or group.
+func RenderD2(g graph.Graph, opts DiagramOptions) string {
+	cyclic, undefined := diagramIssues(g)
+	states := nodeStates(g, opts)
+	alias := make(map[string]string)
+	for _, id := range append(sortedNodeIDs(g), undefined...) {
+		alias[id] = fmt.Sprintf("n%d", len(alias)+1)
+	}
+	for _, id := range undefined {
+		states[id] = stateUndefined
+	}
+
+	var b strings.Builder
+	b.WriteString("direction: down\n")
+	b.WriteString("classes: {\n")
+	for _, c := range d2Classes {
+		b.WriteString("  " + c + "\n")
+	}
+	b.WriteString("}\n")
+
+	// path holds each node's key, qualified by its container
+	path := make(map[string]string)
+	shape := func(indent, id, text, link string) {
+		var attrs []string
+		if state := states[id]; state != "" {
+			attrs = append(attrs, "class: "+state)
+		}
+		if link != "" {
+			attrs = append(attrs, "link: "+strconv.Quote(link))
+		}
+		fmt.Fprintf(&b, "%s%s: %s", indent, alias[id], strconv.Quote(text))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " {%s}", strings.Join(attrs, "; "))
+		}
+		b.WriteString("\n")
+	}
+	groups, members := diagramGroups(g, opts.GroupBy)
+	for i, group := range groups {
+		indent, container := "", ""
+		if group != "" {
+			container = fmt.Sprintf("g%d", i+1)
+			fmt.Fprintf(&b, "%s: %s {\n", container, strconv.Quote(group))
+			indent = "  "
+		}
+		for _, id := range members[group] {
+			path[id] = alias[id]
+			if container != "" {
+				path[id] = container + "." + path[id]
+			}
+			n := g.Nodes[id]
+			shape(indent, id, nodeText(n, opts), nodeLink(n, opts))
+		}
+		if group != "" {
+			b.WriteString("}\n")
+		}
+	}
+	for _, id := range undefined {
+		path[id] = alias[id]
+		shape("", id, id+" (undefined)", "")
+	}
+
+	for _, e := range sortEdges(g.Edges) {
+		fmt.Fprintf(&b, "%s -> %s", path[e.From], path[e.To])
+		var style []string
+		switch {
+		case states[e.From] == stateUndefined || states[e.To] == stateUndefined:
+			style = []string{`stroke: "#d33"`, "stroke-dash: 4"}
+		case cyclic[e]:
+			style = []string{`stroke: "#d33"`}
+		}
+		switch {
+		case opts.EdgeTypes && e.Type != "" && len(style) > 0:
+			fmt.Fprintf(&b, ": %s {style: {%s}}", strconv.Quote(e.Type), strings.Join(style, "; "))
+		case opts.EdgeTypes && e.Type != "":
+			fmt.Fprintf(&b, ": %s", strconv.Quote(e.Type))
+		case len(style) > 0:
+			fmt.Fprintf(&b, ": {style: {%s}}", strings.Join(style, "; "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func TestRenderD2ContainersAndHighlights(t *testing.T) {
+	out := RenderD2(diagramTestGraph(), DiagramOptions{GroupBy: GroupByDir, Labels: true})
+	for _, want := range []string{
+		"g1: \"api\" {\n  n1: \"Build \\\"API\\\"\" {link: \"api/a.go#L1\"}\n}\n",
+		"g2: \"db\" {\n  n2: \"b\" {link: \"db/b.go#L1\"}\n  n3: \"c\" {link: \"db/c.go#L4\"}\n}\n",
+		"n4: \"missing (undefined)\" {class: undefined}\n",
+		"g1.n1 -> g2.n2: {style: {stroke: \"#d33\"}}\n",
+		"g2.n2 -> g2.n3\n",
+		"n4 -> g1.n1: {style: {stroke: \"#d33\"; stroke-dash: 4}}\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderD2KeysCannotCollide(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a":       {ID: "a", File: "db/a.go", Line: 1},
+			"classes": {ID: "classes", File: "x.go", Line: 1},
+		},
+		Edges: []graph.Edge{
+			{From: "db", To: "a", Type: "blocks"},
+			{From: "a", To: "classes", Type: "blocks"},
+		},
+	}
+	out := RenderD2(g, DiagramOptions{GroupBy: GroupByDir})
+	for _, want := range []string{
+		"g2: \"db\" {\n  n1: \"a\" {link: \"db/a.go#L1\"}\n}\n",
+		"n3: \"db (undefined)\" {class: undefined}\n",
+		"n3 -> g2.n1: {style: {stroke: \"#d33\"; stroke-dash: 4}}\n",
+		"g2.n1 -> g1.n2\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\"db\":") || strings.Contains(out, "\"classes\":") {
+		t.Fatalf("expected IDs only as labels, got:\n%s", out)
+	}
+}
+
+func TestRenderD2Options(t *testing.T) {
+	g := diagramTestGraph()
+	g.Nodes["lonely"] = graph.Node{ID: "lonely", File: "z.go", Line: 1}
+	out := RenderD2(g, DiagramOptions{EdgeTypes: true, LinkBase: "https://example.com/"})
+	for _, want := range []string{
+		"n4: \"lonely\" {class: isolated; link: \"https://example.com/z.go#L1\"}\n",
+		"n2 -> n3: \"blocks\"\n",
+		"n1 -> n2: \"blocks\" {style: {stroke: \"#d33\"}}\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+}
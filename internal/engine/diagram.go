@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// Node groupings for diagram exports.
+const (
+	GroupByFile = "file"
+	GroupByDir  = "dir"
+)
+
+// DiagramOptions configure the diagram exporters.
+type DiagramOptions struct {
+	// GroupBy clusters nodes by GroupByFile or GroupByDir; empty draws them
+	// ungrouped.
+	GroupBy string
+	// Labels uses a node's @cgraph-label as its text instead of its ID.
+	Labels bool
+	// EdgeTypes labels each edge with its type.
+	EdgeTypes bool
+	// Report, if set, styles each node by the most severe finding involving
+	// it. Without it, only isolated nodes are marked.
+	Report *CheckReport
+	// LinkBase prefixes the file links of formats that support them, e.g. a
+	// repository URL ending in "/blob/main/". Links are relative without it.
+	LinkBase string
+}
+
+// Node states used to style diagram exports.
+const (
+	stateError     = "error"
+	stateWarn      = "warn"
+	stateIsolated  = "isolated"
+	stateUndefined = "undefined"
+)
+
+// nodeStates returns the state of each node that has one: error or warn
+// from the report's findings, otherwise isolated for nodes without edges.
+// Undefined nodes are reported by diagramIssues instead.
+func nodeStates(g graph.Graph, opts DiagramOptions) map[string]string {
+	states := make(map[string]string)
+	if opts.Report != nil {
+		for _, f := range opts.Report.Findings {
+			for _, id := range f.Nodes {
+				if _, ok := g.Nodes[id]; !ok || states[id] == stateError {
+					continue
+				}
+				if f.Severity == SeverityError {
+					states[id] = stateError
+				} else {
+					states[id] = stateWarn
+				}
+			}
+		}
+	}
+	for _, id := range findIsolated(g) {
+		if states[id] == "" {
+			states[id] = stateIsolated
+		}
+	}
+	return states
+}
+
+// nodeLink is the link to a node's definition.
+func nodeLink(n graph.Node, opts DiagramOptions) string {
+	return fmt.Sprintf("%s%s#L%d", opts.LinkBase, filepath.ToSlash(n.File), n.Line)
+}
+
+// diagramIssues returns the edges that lie on a cycle and the IDs referenced
+// by edges without being defined, sorted. Exporters draw both in red.
+func diagramIssues(g graph.Graph) (cyclic map[graph.Edge]bool, undefined []string) {
+	component := make(map[string]int)
+	for i, c := range findCycleComponents(g) {
+		for _, id := range c.Nodes {
+			component[id] = i
+		}
+	}
+	cyclic = make(map[graph.Edge]bool)
+	missing := make(map[string]bool)
+	for _, e := range g.Edges {
+		from, fromOK := component[e.From]
+		to, toOK := component[e.To]
+		if fromOK && toOK && from == to {
+			cyclic[e] = true
+		}
+		for _, id := range []string{e.From, e.To} {
+			if _, ok := g.Nodes[id]; !ok && !missing[id] {
+				missing[id] = true
+				undefined = append(undefined, id)
+			}
+		}
+	}
+	sort.Strings(undefined)
+	return cyclic, undefined
+}
+
+// diagramGroups returns the group names in order and the sorted node IDs in
+// each. Without a grouping every node is in the single group "".
+func diagramGroups(g graph.Graph, by string) ([]string, map[string][]string) {
+	members := make(map[string][]string)
+	for _, id := range sortedNodeIDs(g) {
+		key := ""
+		switch by {
+		case GroupByFile:
+			key = filepath.ToSlash(g.Nodes[id].File)
+		case GroupByDir:
+			key = path.Dir(filepath.ToSlash(g.Nodes[id].File))
+		}
+		members[key] = append(members[key], id)
+	}
+	keys := make([]string, 0, len(members))
+	for k := range members {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, members
+}
+
+// nodeText is the text drawn for a node.
+func nodeText(n graph.Node, opts DiagramOptions) string {
+	if opts.Labels && n.Label != "" {
+		return n.Label
+	}
+	return n.ID
+}
+
+// analysisNode is a node with the attributes carried by the graph-analysis
+// exports. Undefined nodes have only an ID, used as their label, and the
+// state "undefined".
+type analysisNode struct {
+	ID     string
+	Label  string
+	File   string
+	Line   int
+	Dir    string
+	Tags   []string
+	Ignore []string
+	State  string
+}
+
+// analysisNodes lists the defined nodes in ID order, then the undefined ones,
+// so every edge has both endpoints.
+func analysisNodes(g graph.Graph, opts DiagramOptions) []analysisNode {
+	_, undefined := diagramIssues(g)
+	states := nodeStates(g, opts)
+	var nodes []analysisNode
+	for _, id := range sortedNodeIDs(g) {
+		n := g.Nodes[id]
+		file := filepath.ToSlash(n.File)
+		nodes = append(nodes, analysisNode{
+			ID:     id,
+			Label:  nodeText(n, opts),
+			File:   file,
+			Line:   n.Line,
+			Dir:    path.Dir(file),
+			Tags:   n.Tags,
+			Ignore: n.Ignore,
+			State:  states[id],
+		})
+	}
+	for _, id := range undefined {
+		nodes = append(nodes, analysisNode{ID: id, Label: id, State: stateUndefined})
+	}
+	return nodes
+}
+
+// analysisEdgeID identifies the i-th sorted edge. Node IDs cannot contain '>'
+// or '#', so edge IDs never collide with them.
+func analysisEdgeID(e graph.Edge, i int) string {
+	return fmt.Sprintf("%s->%s#%d", e.From, e.To, i)
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// RenderDOT renders the graph in Graphviz DOT syntax. Nodes are clustered as
+// opts.GroupBy asks and outlined by their state (red for errors, orange for
+// warnings, grey fill when isolated). Edges on a cycle are red, and undefined
+// nodes and the edges reaching them are drawn red and dashed outside any
+// cluster.
+func RenderDOT(g graph.Graph, opts DiagramOptions) string {
+	cyclic, undefined := diagramIssues(g)
+	states := nodeStates(g, opts)
+
+	var b strings.Builder
+	b.WriteString("digraph comment_graph {\n")
+	b.WriteString("  node [shape=box];\n")
+	groups, members := diagramGroups(g, opts.GroupBy)
+	for i, group := range groups {
+		indent := "  "
+		if group != "" {
+			fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+			fmt.Fprintf(&b, "    label=%s;\n", dotQuote(group))
+			indent = "    "
+		}
+		for _, id := range members[group] {
+			n := g.Nodes[id]
+			attrs := []string{
+				"label=" + dotQuote(nodeText(n, opts)),
+				"tooltip=" + dotQuote(fmt.Sprintf("%s:%d", n.File, n.Line)),
+			}
+			if opts.LinkBase != "" {
+				attrs = append(attrs, "URL="+dotQuote(nodeLink(n, opts)))
+			}
+			switch states[id] {
+			case stateError:
+				attrs = append(attrs, "color=red")
+			case stateWarn:
+				attrs = append(attrs, "color=orange")
+			case stateIsolated:
+				attrs = append(attrs, "style=filled", "fillcolor=lightgrey")
+			}
+			fmt.Fprintf(&b, "%s%s [%s];\n", indent, dotQuote(id), strings.Join(attrs, ", "))
+		}
+		if group != "" {
+			b.WriteString("  }\n")
+		}
+	}
+	missing := make(map[string]bool, len(undefined))
+	for _, id := range undefined {
+		missing[id] = true
+		fmt.Fprintf(&b, "  %s [label=%s, color=red, fontcolor=red, style=dashed];\n", dotQuote(id), dotQuote(id+" (undefined)"))
+	}
+
+	for _, e := range sortEdges(g.Edges) {
+		var attrs []string
+		if opts.EdgeTypes && e.Type != "" {
+			attrs = append(attrs, "label="+dotQuote(e.Type))
+		}
+		switch {
+		case missing[e.From] || missing[e.To]:
+			attrs = append(attrs, "color=red", "style=dashed")
+		case cyclic[e]:
+			attrs = append(attrs, "color=red")
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(e.From), dotQuote(e.To), strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(e.From), dotQuote(e.To))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func diagramTestGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a": {ID: "a", File: "api/a.go", Line: 1, Label: `Build "API"`},
+			"b": {ID: "b", File: "db/b.go", Line: 1},
+			"c": {ID: "c", File: "db/c.go", Line: 4},
+		},
+		Edges: []graph.Edge{
+			{From: "b", To: "c", Type: "blocks"},
+			{From: "a", To: "b", Type: "blocks"},
+			{From: "b", To: "a", Type: "blocks"},
+			{From: "missing", To: "a", Type: "blocks"},
+		},
+	}
+}
+
+func TestRenderDOTClustersAndHighlights(t *testing.T) {
+	out := RenderDOT(diagramTestGraph(), DiagramOptions{GroupBy: GroupByDir, Labels: true})
+	for _, want := range []string{
+		"  subgraph cluster_0 {\n    label=\"api\";\n    \"a\" [label=\"Build \\\"API\\\"\", tooltip=\"api/a.go:1\"];\n  }\n",
+		"  subgraph cluster_1 {\n    label=\"db\";\n    \"b\"",
+		`  "missing" [label="missing (undefined)", color=red, fontcolor=red, style=dashed];`,
+		"  \"a\" -> \"b\" [color=red];\n  \"b\" -> \"a\" [color=red];\n  \"b\" -> \"c\";\n",
+		`  "missing" -> "a" [color=red, style=dashed];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderDOTOptions(t *testing.T) {
+	out := RenderDOT(diagramTestGraph(), DiagramOptions{GroupBy: GroupByFile, EdgeTypes: true})
+	if !strings.Contains(out, `label="db/c.go"`) || !strings.Contains(out, `"a" [label="a"`) {
+		t.Fatalf("expected file clusters and ID text, got:\n%s", out)
+	}
+	if !strings.Contains(out, `"b" -> "c" [label="blocks"];`) {
+		t.Fatalf("expected edge type labels, got:\n%s", out)
+	}
+	if out := RenderDOT(diagramTestGraph(), DiagramOptions{}); strings.Contains(out, "subgraph") {
+		t.Fatalf("expected no clusters without grouping, got:\n%s", out)
+	}
+}
+
+func TestRenderDOTStylesNodeStates(t *testing.T) {
+	g := diagramTestGraph()
+	g.Nodes["lonely"] = graph.Node{ID: "lonely", File: "z.go", Line: 1}
+	report := ValidateGraphWithConfig(g, nil, Config{Rules: map[string]Severity{RuleCycle: SeverityWarn}})
+
+	out := RenderDOT(g, DiagramOptions{Report: &report, LinkBase: "https://example.com/"})
+	for _, want := range []string{
+		`"a" [label="a", tooltip="api/a.go:1", URL="https://example.com/api/a.go#L1", color=red];`,
+		`"b" [label="b", tooltip="db/b.go:1", URL="https://example.com/db/b.go#L1", color=orange];`,
+		`"lonely" [label="lonely", tooltip="z.go:1", URL="https://example.com/z.go#L1", color=red];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+	if out := RenderDOT(g, DiagramOptions{}); !strings.Contains(out, `"lonely" [label="lonely", tooltip="z.go:1", style=filled, fillcolor=lightgrey];`) {
+		t.Fatalf("expected isolated node to be filled without a report, got:\n%s", out)
+	}
+}
@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestRenderGEXFCarriesAttributes(t *testing.T) {
+	data, err := RenderGEXF(diagramTestGraph(), DiagramOptions{Labels: true})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	var doc gexf
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("decode: %v\n%s", err, data)
+	}
+	if doc.Version != "1.3" || doc.Graph.DefaultEdgeType != "directed" || len(doc.Graph.Attributes) != 2 {
+		t.Fatalf("expected a directed GEXF 1.3 graph with node and edge attributes, got %+v", doc)
+	}
+	a := doc.Graph.Nodes[0]
+	if a.ID != "a" || a.Label != `Build "API"` {
+		t.Fatalf("expected node a labelled from @cgraph-label, got %+v", a)
+	}
+	values := map[string]string{}
+	for _, v := range a.Values {
+		values[v.For] = v.Value
+	}
+	if values["file"] != "api/a.go" || values["line"] != "1" || values["dir"] != "api" {
+		t.Fatalf("expected file, line and dir values, got %v", values)
+	}
+	if len(doc.Graph.Nodes) != 4 || doc.Graph.Nodes[3].Label != "missing" {
+		t.Fatalf("expected undefined node last, got %+v", doc.Graph.Nodes)
+	}
+	e := doc.Graph.Edges[0]
+	if e.Source != "a" || e.Target != "b" || e.Label != "blocks" || len(e.Values) != 1 {
+		t.Fatalf("expected typed a -> b edge, got %+v", e)
+	}
+}
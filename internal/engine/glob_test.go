@@ -0,0 +1,27 @@
+package engine
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.min.js", "web/dist/app.min.js", true},
+		{"*.min.js", "web/app.js", false},
+		{"dist", "web/dist", true},
+		{"web/*", "web/app.ts", true},
+		{"web/*", "web/src/app.ts", false},
+		{"web/**", "web/src/app.ts", true},
+		{"web/**/*.ts", "web/app.ts", true},
+		{"**/fixtures", "a/b/fixtures", true},
+		{"./gen/", "gen", true},
+		{"[", "a", false},
+	}
+	for _, tt := range cases {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
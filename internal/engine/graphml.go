@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphMLKeys declares the node and edge attributes shared by the GraphML
+// and GEXF exports. Tags and ignore are comma-separated lists.
+var graphMLKeys = []graphMLKey{
+	{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+	{ID: "file", For: "node", AttrName: "file", AttrType: "string"},
+	{ID: "line", For: "node", AttrName: "line", AttrType: "int"},
+	{ID: "dir", For: "node", AttrName: "dir", AttrType: "string"},
+	{ID: "tags", For: "node", AttrName: "tags", AttrType: "string"},
+	{ID: "ignore", For: "node", AttrName: "ignore", AttrType: "string"},
+	{ID: "state", For: "node", AttrName: "state", AttrType: "string"},
+	{ID: "type", For: "edge", AttrName: "type", AttrType: "string"},
+}
+
+// RenderGraphML renders the graph as GraphML for tools such as yEd and
+// Gephi. Nodes carry their label, file, line, directory, tags, ignored rules
+// and state (see DiagramOptions.Report); edges carry their type and run from
+// the dependency to the dependant. Undefined references become nodes with
+// the state "undefined".
+func RenderGraphML(g graph.Graph, opts DiagramOptions) ([]byte, error) {
+	out := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{ID: "comment-graph", EdgeDefault: "directed"},
+	}
+	for _, n := range analysisNodes(g, opts) {
+		out.Graph.Nodes = append(out.Graph.Nodes, graphMLNode{ID: n.ID, Data: nodeAttributes(n)})
+	}
+	for i, e := range sortEdges(g.Edges) {
+		edge := graphMLEdge{ID: analysisEdgeID(e, i), Source: e.From, Target: e.To}
+		if e.Type != "" {
+			edge.Data = []graphMLData{{Key: "type", Value: e.Type}}
+		}
+		out.Graph.Edges = append(out.Graph.Edges, edge)
+	}
+
+	data, err := xml.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// nodeAttributes returns the non-empty attributes of a node, keyed as in
+// graphMLKeys.
+func nodeAttributes(n analysisNode) []graphMLData {
+	var attrs []graphMLData
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, graphMLData{Key: key, Value: value})
+		}
+	}
+	add("label", n.Label)
+	add("file", n.File)
+	if n.Line > 0 {
+		add("line", strconv.Itoa(n.Line))
+	}
+	add("dir", n.Dir)
+	add("tags", strings.Join(n.Tags, ","))
+	add("ignore", strings.Join(n.Ignore, ","))
+	add("state", n.State)
+	return attrs
+}
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func TestRenderGraphMLCarriesAttributes(t *testing.T) {
+	g := diagramTestGraph()
+	a := g.Nodes["a"]
+	a.Tags, a.Ignore = []string{"web", "core"}, []string{"isolated"}
+	g.Nodes["a"] = a
+
+	data, err := RenderGraphML(g, DiagramOptions{Labels: true})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	var doc graphML
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("decode: %v\n%s", err, data)
+	}
+	if doc.Graph.EdgeDefault != "directed" || len(doc.Keys) != len(graphMLKeys) {
+		t.Fatalf("expected a directed graph with declared keys, got %+v", doc)
+	}
+	ids := []string{}
+	for _, n := range doc.Graph.Nodes {
+		ids = append(ids, n.ID)
+	}
+	if strings.Join(ids, ",") != "a,b,c,missing" {
+		t.Fatalf("expected defined then undefined nodes, got %v", ids)
+	}
+	attrs := map[string]string{}
+	for _, d := range doc.Graph.Nodes[0].Data {
+		attrs[d.Key] = d.Value
+	}
+	want := map[string]string{"label": `Build "API"`, "file": "api/a.go", "line": "1", "dir": "api", "tags": "web,core", "ignore": "isolated"}
+	for k, v := range want {
+		if attrs[k] != v {
+			t.Fatalf("expected %s=%q, got %v", k, v, attrs)
+		}
+	}
+	if missing := doc.Graph.Nodes[3].Data; len(missing) != 2 || missing[1].Value != stateUndefined {
+		t.Fatalf("expected undefined node with label and state, got %+v", missing)
+	}
+	e := doc.Graph.Edges[0]
+	if e.Source != "a" || e.Target != "b" || len(e.Data) != 1 || e.Data[0].Value != "blocks" {
+		t.Fatalf("expected typed a -> b edge, got %+v", e)
+	}
+}
+
+func TestAnalysisEdgeIDsDoNotCollideWithNodes(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"e0": {ID: "e0", File: "a.go", Line: 1},
+			"e1": {ID: "e1", File: "a.go", Line: 2},
+		},
+		Edges: []graph.Edge{{From: "e0", To: "e1", Type: "blocks"}, {From: "e1", To: "e0", Type: "blocks"}},
+	}
+	check := func(format string, nodes, edges []string) {
+		t.Helper()
+		seen := map[string]bool{}
+		for _, id := range append(nodes, edges...) {
+			if seen[id] {
+				t.Fatalf("%s: duplicate id %q among nodes %v and edges %v", format, id, nodes, edges)
+			}
+			seen[id] = true
+		}
+		if len(edges) != 2 || edges[0] != "e0->e1#0" {
+			t.Fatalf("%s: unexpected edge ids %v", format, edges)
+		}
+	}
+
+	data, err := RenderGraphML(g, DiagramOptions{})
+	if err != nil {
+		t.Fatalf("render graphml: %v", err)
+	}
+	var ml graphML
+	if err := xml.Unmarshal(data, &ml); err != nil {
+		t.Fatalf("decode graphml: %v", err)
+	}
+	var nodes, edges []string
+	for _, n := range ml.Graph.Nodes {
+		nodes = append(nodes, n.ID)
+	}
+	for _, e := range ml.Graph.Edges {
+		edges = append(edges, e.ID)
+	}
+	check("graphml", nodes, edges)
+
+	if data, err = RenderGEXF(g, DiagramOptions{}); err != nil {
+		t.Fatalf("render gexf: %v", err)
+	}
+	var gx gexf
+	if err := xml.Unmarshal(data, &gx); err != nil {
+		t.Fatalf("decode gexf: %v", err)
+	}
+	nodes, edges = nil, nil
+	for _, n := range gx.Graph.Nodes {
+		nodes = append(nodes, n.ID)
+	}
+	for _, e := range gx.Graph.Edges {
+		edges = append(edges, e.ID)
+	}
+	check("gexf", nodes, edges)
+
+	if data, err = RenderCytoscape(g, DiagramOptions{}); err != nil {
+		t.Fatalf("render cytoscape: %v", err)
+	}
+	var cy cytoscapeGraph
+	if err := json.Unmarshal(data, &cy); err != nil {
+		t.Fatalf("decode cytoscape: %v", err)
+	}
+	nodes, edges = nil, nil
+	for _, n := range cy.Elements.Nodes {
+		nodes = append(nodes, n.Data.ID)
+	}
+	for _, e := range cy.Elements.Edges {
+		edges = append(edges, e.Data.ID)
+	}
+	check("cytoscape", nodes, edges)
+}
@@ -0,0 +1,241 @@
+package engine
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"sort"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+//go:embed report/report.html
+var reportPage string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportPage))
+
+// Layout of the HTML report, in pixels between node centres.
+const (
+	layoutColumn     = 200
+	layoutRow        = 90
+	layoutMaxColumns = 16
+)
+
+type reportData struct {
+	Title    string       `json:"title"`
+	Nodes    []reportNode `json:"nodes"`
+	Edges    []reportEdge `json:"edges"`
+	Findings []Finding    `json:"findings"`
+	Dirs     []string     `json:"dirs"`
+	Tags     []string     `json:"tags"`
+}
+
+type reportNode struct {
+	ID    string   `json:"id"`
+	Label string   `json:"label"`
+	File  string   `json:"file,omitempty"`
+	Line  int      `json:"line,omitempty"`
+	Dir   string   `json:"dir,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+	State string   `json:"state,omitempty"`
+	Link  string   `json:"link,omitempty"`
+	X     int      `json:"x"`
+	Y     int      `json:"y"`
+}
+
+type reportEdge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Type      string `json:"type,omitempty"`
+	Cyclic    bool   `json:"cyclic,omitempty"`
+	Undefined bool   `json:"undefined,omitempty"`
+}
+
+// RenderHTMLReport renders a self-contained HTML page for browsing the
+// graph: the nodes laid out in rows by dependency depth and styled like the
+// diagram exports, with search, directory and tag filters, highlighting of a
+// clicked node's ancestors and descendants, and a panel listing the report's
+// findings. Scripts, styles and data are all inline, so the page works
+// offline. Node links follow opts.LinkBase; the other options are ignored.
+func RenderHTMLReport(g graph.Graph, report CheckReport, title string, opts DiagramOptions) ([]byte, error) {
+	opts.Labels = true
+	opts.Report = &report
+	cyclic, _ := diagramIssues(g)
+
+	data := reportData{Title: title, Findings: report.Findings, Dirs: []string{}, Tags: []string{}}
+	if data.Findings == nil {
+		data.Findings = []Finding{}
+	}
+	nodes := analysisNodes(g, opts)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Dir != nodes[j].Dir {
+			return nodes[i].Dir < nodes[j].Dir
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+	ids := make([]string, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	edges := sortEdges(g.Edges)
+	points := layeredLayout(g, ids, edges)
+
+	dirs := make(map[string]bool)
+	tags := make(map[string]bool)
+	for _, n := range nodes {
+		rn := reportNode{
+			ID:    n.ID,
+			Label: n.Label,
+			File:  n.File,
+			Line:  n.Line,
+			Dir:   n.Dir,
+			Tags:  n.Tags,
+			State: n.State,
+			X:     points[n.ID].X,
+			Y:     points[n.ID].Y,
+		}
+		if gn, ok := g.Nodes[n.ID]; ok {
+			rn.Link = nodeLink(gn, opts)
+			dirs[n.Dir] = true
+		}
+		for _, t := range n.Tags {
+			tags[t] = true
+		}
+		data.Nodes = append(data.Nodes, rn)
+	}
+	for _, e := range edges {
+		_, fromOK := g.Nodes[e.From]
+		_, toOK := g.Nodes[e.To]
+		data.Edges = append(data.Edges, reportEdge{
+			From:      e.From,
+			To:        e.To,
+			Type:      e.Type,
+			Cyclic:    cyclic[e],
+			Undefined: !fromOK || !toOK,
+		})
+	}
+	for d := range dirs {
+		data.Dirs = append(data.Dirs, d)
+	}
+	for t := range tags {
+		data.Tags = append(data.Tags, t)
+	}
+	sort.Strings(data.Dirs)
+	sort.Strings(data.Tags)
+	if data.Nodes == nil {
+		data.Nodes = []reportNode{}
+	}
+	if data.Edges == nil {
+		data.Edges = []reportEdge{}
+	}
+
+	var b bytes.Buffer
+	if err := reportTemplate.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// layoutPoint is the centre of a node in the HTML report.
+type layoutPoint struct {
+	X, Y int
+}
+
+// layeredLayout places nodes in rows by dependency depth: nodes without
+// dependencies on top and every other node below all of its dependencies,
+// with the members of a cycle sharing a row. Each row starts in the order of
+// ids and is then sorted by the mean column of the nodes' dependencies to
+// keep edges short. Rows are centred and wrap after layoutMaxColumns nodes.
+func layeredLayout(g graph.Graph, ids []string, edges []graph.Edge) map[string]layoutPoint {
+	unit := make(map[string]int)
+	components := findCycleComponents(g)
+	for i, c := range components {
+		for _, id := range c.Nodes {
+			unit[id] = i
+		}
+	}
+	units := len(components)
+	for _, id := range ids {
+		if _, ok := unit[id]; !ok {
+			unit[id] = units
+			units++
+		}
+	}
+
+	succ := make([][]int, units)
+	indegree := make([]int, units)
+	seen := make(map[[2]int]bool)
+	preds := make(map[string][]string)
+	for _, e := range edges {
+		from, fromOK := unit[e.From]
+		to, toOK := unit[e.To]
+		if !fromOK || !toOK {
+			continue
+		}
+		preds[e.To] = append(preds[e.To], e.From)
+		if from == to || seen[[2]int{from, to}] {
+			continue
+		}
+		seen[[2]int{from, to}] = true
+		succ[from] = append(succ[from], to)
+		indegree[to]++
+	}
+	level := make([]int, units)
+	var queue []int
+	for u := 0; u < units; u++ {
+		if indegree[u] == 0 {
+			queue = append(queue, u)
+		}
+	}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range succ[u] {
+			level[v] = max(level[v], level[u]+1)
+			if indegree[v]--; indegree[v] == 0 {
+				queue = append(queue, v)
+			}
+		}
+	}
+
+	var rows [][]string
+	for _, id := range ids {
+		l := level[unit[id]]
+		for len(rows) <= l {
+			rows = append(rows, nil)
+		}
+		rows[l] = append(rows[l], id)
+	}
+
+	points := make(map[string]layoutPoint, len(ids))
+	column := make(map[string]float64, len(ids))
+	y := 0
+	for _, row := range rows {
+		key := make(map[string]float64, len(row))
+		for i, id := range row {
+			key[id] = float64(len(ids) + i)
+			sum, n := 0.0, 0
+			for _, p := range preds[id] {
+				if c, ok := column[p]; ok {
+					sum += c
+					n++
+				}
+			}
+			if n > 0 {
+				key[id] = sum / float64(n)
+			}
+		}
+		sort.SliceStable(row, func(i, j int) bool { return key[row[i]] < key[row[j]] })
+
+		for start := 0; start < len(row); start += layoutMaxColumns {
+			line := row[start:min(start+layoutMaxColumns, len(row))]
+			offset := float64(layoutMaxColumns-len(line)) / 2
+			for i, id := range line {
+				column[id] = offset + float64(i)
+				points[id] = layoutPoint{X: int(column[id]*layoutColumn) + layoutColumn/2, Y: y + layoutRow/2}
+			}
+			y += layoutRow
+		}
+	}
+	return points
+}
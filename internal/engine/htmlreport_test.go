@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func TestLayeredLayoutRowsByDepth(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"root": {ID: "root"}, "mid": {ID: "mid"}, "leaf": {ID: "leaf"},
+			"x": {ID: "x"}, "y": {ID: "y"},
+		},
+		Edges: []graph.Edge{
+			{From: "root", To: "mid"},
+			{From: "mid", To: "leaf"},
+			{From: "root", To: "leaf"},
+			{From: "root", To: "x"},
+			{From: "x", To: "y"},
+			{From: "y", To: "x"},
+		},
+	}
+	points := layeredLayout(g, sortedNodeIDs(g), g.Edges)
+	row := func(id string) int { return points[id].Y / layoutRow }
+	if row("root") != 0 || row("mid") != 1 || row("leaf") != 2 {
+		t.Fatalf("expected rows by longest dependency chain, got %v", points)
+	}
+	if row("x") != 1 || row("y") != 1 || points["x"].X == points["y"].X {
+		t.Fatalf("expected cycle members side by side in one row, got %v", points)
+	}
+}
+
+func TestLayeredLayoutWrapsWideRows(t *testing.T) {
+	g := graph.Graph{Nodes: map[string]graph.Node{}}
+	var ids []string
+	for i := 0; i < layoutMaxColumns+1; i++ {
+		id := string(rune('a'+i/26)) + string(rune('a'+i%26))
+		g.Nodes[id] = graph.Node{ID: id}
+		ids = append(ids, id)
+	}
+	points := layeredLayout(g, ids, nil)
+	if points[ids[0]].Y == points[ids[layoutMaxColumns]].Y {
+		t.Fatalf("expected node %d to wrap onto a new line, got %v", layoutMaxColumns, points)
+	}
+}
+
+func TestRenderHTMLReportIsSelfContained(t *testing.T) {
+	g := diagramTestGraph()
+	a := g.Nodes["a"]
+	a.Label = `</script><img src=x onerror=alert(1)>`
+	a.Tags = []string{"web"}
+	g.Nodes["a"] = a
+	report := ValidateGraph(g, nil)
+
+	page, err := RenderHTMLReport(g, report, "Plans <draft>", DiagramOptions{LinkBase: "https://example.com/"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := string(page)
+	for _, want := range []string{
+		"<title>Plans &lt;draft&gt;</title>",
+		`"id":"missing"`,
+		`"dirs":["api","db"]`,
+		`"tags":["web"]`,
+		`"link":"https://example.com/api/a.go#L1"`,
+		`"code":"CG011"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in report", want)
+		}
+	}
+	if strings.Count(out, "</script>") != 1 || strings.Contains(out, "<img") {
+		t.Fatalf("expected labels to be escaped inside the script")
+	}
+	for _, external := range []string{"<script src", "<link", "@import", "url(http"} {
+		if strings.Contains(out, external) {
+			t.Fatalf("expected no external resources, found %q", external)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// Limits bound the shape of the graph. Zero disables a limit.
+type Limits struct {
+	// MaxDepth is the longest allowed chain of blocks edges; a -> b -> c has
+	// depth 2.
+	MaxDepth int
+	// MaxFanIn is the most dependencies a node may declare.
+	MaxFanIn int
+	// MaxFanOut is the most dependants a node may have.
+	MaxFanOut int
+}
+
+// adjacency maps each node to the defined nodes it blocks, sorted, and each
+// node to the defined nodes blocking it.
+func adjacency(g graph.Graph) (blocks, blockedBy map[string][]string) {
+	blocks = make(map[string][]string)
+	blockedBy = make(map[string][]string)
+	for _, e := range normalizeEdges(g.Edges) {
+		if _, ok := g.Nodes[e.From]; !ok {
+			continue
+		}
+		if _, ok := g.Nodes[e.To]; !ok {
+			continue
+		}
+		blocks[e.From] = append(blocks[e.From], e.To)
+		blockedBy[e.To] = append(blockedBy[e.To], e.From)
+	}
+	for id := range blockedBy {
+		sort.Strings(blockedBy[id])
+	}
+	return blocks, blockedBy
+}
+
+// findLimitViolations reports nodes whose fan-in or fan-out exceeds limits
+// and chains deeper than limits.MaxDepth. Nodes on or behind a cycle are not
+// measured for depth; the cycle rule covers them.
+func findLimitViolations(g graph.Graph, limits Limits) (depth, fanIn, fanOut []Finding) {
+	if limits == (Limits{}) {
+		return nil, nil, nil
+	}
+	blocks, blockedBy := adjacency(g)
+	ids := sortedNodeIDs(g)
+
+	for _, id := range ids {
+		if deps := blockedBy[id]; limits.MaxFanIn > 0 && len(deps) > limits.MaxFanIn {
+			fanIn = append(fanIn, neighbourFinding(g, id, deps,
+				fmt.Sprintf("%q has %d dependencies, more than max-fan-in %d", id, len(deps), limits.MaxFanIn)))
+		}
+		if dependants := blocks[id]; limits.MaxFanOut > 0 && len(dependants) > limits.MaxFanOut {
+			fanOut = append(fanOut, neighbourFinding(g, id, dependants,
+				fmt.Sprintf("%q blocks %d nodes, more than max-fan-out %d", id, len(dependants), limits.MaxFanOut)))
+		}
+	}
+
+	if limits.MaxDepth > 0 {
+		for _, chain := range deepChains(ids, blocks, blockedBy, limits.MaxDepth) {
+			depth = append(depth, Finding{
+				Message: fmt.Sprintf("chain of depth %d exceeds max-depth %d: %s",
+					len(chain)-1, limits.MaxDepth, strings.Join(chain, " -> ")),
+				Nodes:     chain,
+				Locations: nodeLocations(g, chain),
+			})
+		}
+	}
+	return depth, fanIn, fanOut
+}
+
+func neighbourFinding(g graph.Graph, id string, neighbours []string, msg string) Finding {
+	nodes := append([]string{id}, neighbours...)
+	return Finding{
+		Message:   msg + ": " + strings.Join(neighbours, ", "),
+		Nodes:     nodes,
+		Locations: nodeLocations(g, nodes),
+	}
+}
+
+// deepChains returns, for every node ending a chain longer than limit, the
+// longest chain leading to it. Nodes are visited in topological order, taking
+// the lexically smallest predecessor among equally deep ones.
+func deepChains(ids []string, blocks, blockedBy map[string][]string, limit int) [][]string {
+	indegree := make(map[string]int, len(ids))
+	for _, id := range ids {
+		indegree[id] = len(blockedBy[id])
+	}
+	queue := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if indegree[id] == 0 {
+			queue = append(queue, id)
+		}
+	}
+
+	depth := make(map[string]int, len(ids))
+	prev := make(map[string]string)
+	done := make(map[string]bool, len(ids))
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		done[id] = true
+		for _, next := range blocks[id] {
+			if d := depth[id] + 1; d > depth[next] || (d == depth[next] && id < prev[next]) {
+				depth[next] = d
+				prev[next] = id
+			}
+			indegree[next]--
+			if indegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	var chains [][]string
+	for _, id := range ids {
+		if !done[id] || depth[id] <= limit {
+			continue
+		}
+		ends := true
+		for _, next := range blocks[id] {
+			if done[next] {
+				ends = false
+				break
+			}
+		}
+		if !ends {
+			continue
+		}
+		chain := []string{id}
+		for at := id; prev[at] != ""; at = prev[at] {
+			chain = append(chain, prev[at])
+		}
+		for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+			chain[i], chain[j] = chain[j], chain[i]
+		}
+		chains = append(chains, chain)
+	}
+	return chains
+}
+
+func parseLimits(name string, v *yamlValue) (Limits, error) {
+	if v.kind != yamlMap {
+		return Limits{}, fmt.Errorf("%s:%d: limits must be a mapping", name, v.line)
+	}
+	var limits Limits
+	for _, key := range v.keys {
+		fv := v.fields[key]
+		n, err := strconv.Atoi(fv.scalar)
+		if fv.kind != yamlScalar || err != nil || n < 0 {
+			return Limits{}, fmt.Errorf("%s:%d: %s must be a non-negative integer", name, fv.line, key)
+		}
+		switch key {
+		case RuleMaxDepth:
+			limits.MaxDepth = n
+		case RuleMaxFanIn:
+			limits.MaxFanIn = n
+		case RuleMaxFanOut:
+			limits.MaxFanOut = n
+		default:
+			return Limits{}, fmt.Errorf("%s:%d: unknown limit %q (use max-depth, max-fan-in, max-fan-out)", name, fv.line, key)
+		}
+	}
+	return limits, nil
+}
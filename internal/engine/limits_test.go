@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func TestLimitsReportDepthAndFanOut(t *testing.T) {
+	cfg, err := parseConfig(ConfigFile, []byte("limits:\n  max-depth: 2\n  max-fan-in: 2\n  max-fan-out: 2\n"))
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+
+	nodes := map[string]graph.Node{}
+	for _, id := range []string{"a", "b", "c", "d", "e", "x", "y", "z"} {
+		nodes[id] = graph.Node{ID: id, File: id + ".go", Line: 1}
+	}
+	g := graph.Graph{
+		Nodes: nodes,
+		Edges: []graph.Edge{
+			// a -> b -> c -> d, with a shortcut a -> c
+			{From: "a", To: "b", Type: "blocks"},
+			{From: "b", To: "c", Type: "blocks"},
+			{From: "a", To: "c", Type: "blocks"},
+			{From: "c", To: "d", Type: "blocks"},
+			// x blocks three nodes; e waits on three
+			{From: "x", To: "e", Type: "blocks"},
+			{From: "x", To: "y", Type: "blocks"},
+			{From: "x", To: "z", Type: "blocks"},
+			{From: "y", To: "e", Type: "blocks"},
+			{From: "z", To: "e", Type: "blocks"},
+		},
+	}
+
+	report := ValidateGraphWithConfig(g, nil, cfg)
+	byRule := map[string][]Finding{}
+	for _, f := range report.RuleErrors() {
+		byRule[f.Rule] = append(byRule[f.Rule], f)
+	}
+
+	depth := byRule[RuleMaxDepth]
+	if len(depth) != 1 || strings.Join(depth[0].Nodes, ",") != "a,b,c,d" {
+		t.Fatalf("expected chain a,b,c,d, got %+v", depth)
+	}
+	if !strings.Contains(depth[0].Message, "depth 3 exceeds max-depth 2") {
+		t.Fatalf("unexpected message: %s", depth[0].Message)
+	}
+	fanIn := byRule[RuleMaxFanIn]
+	if len(fanIn) != 1 || strings.Join(fanIn[0].Nodes, ",") != "e,x,y,z" {
+		t.Fatalf("expected e to exceed fan-in with its dependencies, got %+v", fanIn)
+	}
+	fanOut := byRule[RuleMaxFanOut]
+	if len(fanOut) != 1 || strings.Join(fanOut[0].Nodes, ",") != "x,e,y,z" || len(fanOut[0].Locations) != 4 {
+		t.Fatalf("expected x to exceed fan-out with its dependants, got %+v", fanOut)
+	}
+}
+
+func TestLimitsIgnoreNodesBehindCycles(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a": {ID: "a"}, "b": {ID: "b"}, "c": {ID: "c"},
+		},
+		Edges: []graph.Edge{
+			{From: "a", To: "b", Type: "blocks"},
+			{From: "b", To: "a", Type: "blocks"},
+			{From: "b", To: "c", Type: "blocks"},
+		},
+	}
+	depth, _, _ := findLimitViolations(g, Limits{MaxDepth: 1})
+	if len(depth) != 0 {
+		t.Fatalf("expected no depth findings through a cycle, got %+v", depth)
+	}
+}
+
+func TestParseLimitsErrors(t *testing.T) {
+	for content, want := range map[string]string{
+		"limits:\n  max-depth: deep\n": "max-depth must be a non-negative integer",
+		"limits:\n  max-width: 3\n":    `unknown limit "max-width"`,
+		"limits: [max-depth]\n":        "limits must be a mapping",
+		"limits:\n  max-fan-out: -1\n": "max-fan-out must be a non-negative integer",
+	} {
+		_, err := parseConfig(ConfigFile, []byte(content))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
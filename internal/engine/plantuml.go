@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// RenderPlantUML renders the graph as a PlantUML diagram: a rectangle per
+// node, a package per group, a stereotype for its state (styled, then
+// hidden) and a link to its file and line. Edges on a cycle are red, and
+// undefined nodes and the edges reaching them are red and dashed. PlantUML
+// aliases cannot hold every ID, so nodes are aliased n1, n2, ... in ID
+// order, undefined ones last.
+func RenderPlantUML(g graph.Graph, opts DiagramOptions) string {
+	cyclic, undefined := diagramIssues(g)
+	states := nodeStates(g, opts)
+	alias := make(map[string]string)
+	for _, id := range append(sortedNodeIDs(g), undefined...) {
+		alias[id] = fmt.Sprintf("n%d", len(alias)+1)
+	}
+	for _, id := range undefined {
+		states[id] = stateUndefined
+	}
+
+	var b strings.Builder
+	b.WriteString("@startuml\n")
+	b.WriteString("hide stereotype\n")
+	b.WriteString("skinparam rectangle {\n")
+	b.WriteString("  BorderColor<<error>> #d33\n")
+	b.WriteString("  BorderThickness<<error>> 2\n")
+	b.WriteString("  BorderColor<<warn>> #e90\n")
+	b.WriteString("  BorderThickness<<warn>> 2\n")
+	b.WriteString("  BackgroundColor<<isolated>> #eee\n")
+	b.WriteString("  BorderColor<<undefined>> #d33\n")
+	b.WriteString("  FontColor<<undefined>> #d33\n")
+	b.WriteString("  BorderStyle<<undefined>> dashed\n")
+	b.WriteString("}\n")
+
+	rectangle := func(indent, id, text, link string) {
+		fmt.Fprintf(&b, "%srectangle %s as %s", indent, plantUMLQuote(text), alias[id])
+		if state := states[id]; state != "" {
+			fmt.Fprintf(&b, " <<%s>>", state)
+		}
+		if link != "" {
+			fmt.Fprintf(&b, " [[%s]]", link)
+		}
+		b.WriteString("\n")
+	}
+	groups, members := diagramGroups(g, opts.GroupBy)
+	for _, group := range groups {
+		indent := ""
+		if group != "" {
+			fmt.Fprintf(&b, "package %s {\n", plantUMLQuote(group))
+			indent = "  "
+		}
+		for _, id := range members[group] {
+			n := g.Nodes[id]
+			rectangle(indent, id, nodeText(n, opts), nodeLink(n, opts))
+		}
+		if group != "" {
+			b.WriteString("}\n")
+		}
+	}
+	for _, id := range undefined {
+		rectangle("", id, id+" (undefined)", "")
+	}
+
+	for _, e := range sortEdges(g.Edges) {
+		arrow := "-->"
+		switch {
+		case states[e.From] == stateUndefined || states[e.To] == stateUndefined:
+			arrow = "-[#d33,dashed]->"
+		case cyclic[e]:
+			arrow = "-[#d33]->"
+		}
+		fmt.Fprintf(&b, "%s %s %s", alias[e.From], arrow, alias[e.To])
+		if opts.EdgeTypes && e.Type != "" {
+			b.WriteString(" : " + e.Type)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("@enduml\n")
+	return b.String()
+}
+
+// plantUMLQuote quotes text; PlantUML has no escape for a double quote, so
+// it becomes a single one.
+func plantUMLQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "'") + `"`
+}
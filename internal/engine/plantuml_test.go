@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func TestRenderPlantUMLPackagesAndHighlights(t *testing.T) {
+	out := RenderPlantUML(diagramTestGraph(), DiagramOptions{GroupBy: GroupByDir, Labels: true})
+	if !strings.HasPrefix(out, "@startuml\n") || !strings.HasSuffix(out, "@enduml\n") {
+		t.Fatalf("expected a @startuml/@enduml block, got:\n%s", out)
+	}
+	for _, want := range []string{
+		"package \"api\" {\n  rectangle \"Build 'API'\" as n1 [[api/a.go#L1]]\n}\n",
+		"package \"db\" {\n  rectangle \"b\" as n2 [[db/b.go#L1]]\n  rectangle \"c\" as n3 [[db/c.go#L4]]\n}\n",
+		"rectangle \"missing (undefined)\" as n4 <<undefined>>\n",
+		"n1 -[#d33]-> n2\nn2 -[#d33]-> n1\nn2 --> n3\n",
+		"n4 -[#d33,dashed]-> n1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderPlantUMLOptions(t *testing.T) {
+	g := diagramTestGraph()
+	g.Nodes["lonely"] = graph.Node{ID: "lonely", File: "z.go", Line: 1}
+	out := RenderPlantUML(g, DiagramOptions{EdgeTypes: true, LinkBase: "https://example.com/"})
+	if strings.Contains(out, "package") {
+		t.Fatalf("expected no packages without grouping, got:\n%s", out)
+	}
+	for _, want := range []string{
+		`rectangle "a" as n1 [[https://example.com/api/a.go#L1]]`,
+		`rectangle "lonely" as n4 <<isolated>> [[https://example.com/z.go#L1]]`,
+		"n2 --> n3 : blocks\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package engine
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// Policy restricts what the nodes it selects may depend on. A node depends on
+// another when it lists it in @cgraph-deps, i.e. it is the To end of a blocks
+// edge.
+type Policy struct {
+	Name string
+	// Nodes selects the dependants the policy applies to.
+	Nodes Selector
+	// Allow, if set, is the only set of nodes the selected nodes may depend on.
+	Allow *Selector
+	// Deny is a set of nodes the selected nodes must not depend on.
+	Deny *Selector
+}
+
+// Selector matches nodes by path, ID and tags. Every non-empty criterion must
+// match: the file must match one of Paths, the ID one of IDs, and the node
+// must carry all Tags.
+type Selector struct {
+	Paths []string
+	IDs   []string
+	Tags  []string
+}
+
+// Match reports whether n is selected. Paths are slash-separated globs,
+// matched against the slash form of the node's file on every OS.
+func (s Selector) Match(n graph.Node) bool {
+	file := filepath.ToSlash(n.File)
+	if len(s.Paths) > 0 && !slices.ContainsFunc(s.Paths, func(p string) bool { return matchGlob(p, file) }) {
+		return false
+	}
+	if len(s.IDs) > 0 && !slices.ContainsFunc(s.IDs, func(p string) bool {
+		ok, _ := path.Match(p, n.ID)
+		return ok
+	}) {
+		return false
+	}
+	for _, tag := range s.Tags {
+		if !slices.Contains(n.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// violates reports whether a node selected by p may not depend on dep.
+func (p Policy) violates(dep graph.Node) bool {
+	if p.Deny != nil && p.Deny.Match(dep) {
+		return true
+	}
+	return p.Allow != nil && !p.Allow.Match(dep)
+}
+
+// findPolicyViolations checks every blocks edge between defined nodes against
+// policies, returning one finding per edge and violated policy.
+func findPolicyViolations(g graph.Graph, policies []Policy) []Finding {
+	if len(policies) == 0 {
+		return nil
+	}
+	edges := normalizeEdges(g.Edges)
+	var out []Finding
+	for _, e := range edges {
+		if e.Type != "" && e.Type != "blocks" {
+			continue
+		}
+		dep, depOK := g.Nodes[e.From]
+		node, nodeOK := g.Nodes[e.To]
+		if !depOK || !nodeOK {
+			continue
+		}
+		for _, p := range policies {
+			if !p.Nodes.Match(node) || !p.violates(dep) {
+				continue
+			}
+			out = append(out, Finding{
+				Message: fmt.Sprintf("%q (%s) must not depend on %q (%s): %s",
+					node.ID, node.File, dep.ID, dep.File, p.Name),
+				Nodes:     []string{node.ID, dep.ID},
+				Locations: nodeLocations(g, []string{node.ID, dep.ID}),
+			})
+		}
+	}
+	return out
+}
+
+func parsePolicies(name string, v *yamlValue) ([]Policy, error) {
+	if v.kind != yamlList {
+		return nil, fmt.Errorf("%s:%d: policies must be a list", name, v.line)
+	}
+	var policies []Policy
+	for i, item := range v.items {
+		if item.kind != yamlMap {
+			return nil, fmt.Errorf("%s:%d: policy must be a mapping", name, item.line)
+		}
+		p := Policy{Name: fmt.Sprintf("policy %d", i+1)}
+		hasNodes := false
+		for _, key := range item.keys {
+			fv := item.fields[key]
+			switch key {
+			case "name":
+				if fv.kind != yamlScalar || fv.scalar == "" {
+					return nil, fmt.Errorf("%s:%d: policy name must be a string", name, fv.line)
+				}
+				p.Name = fv.scalar
+			case "nodes", "allow", "deny":
+				s, err := parseSelector(name, key, fv)
+				if err != nil {
+					return nil, err
+				}
+				switch key {
+				case "nodes":
+					p.Nodes = s
+					hasNodes = true
+				case "allow":
+					p.Allow = &s
+				default:
+					p.Deny = &s
+				}
+			default:
+				return nil, fmt.Errorf("%s:%d: unknown policy setting %q (use name, nodes, allow, deny)", name, fv.line, key)
+			}
+		}
+		if !hasNodes {
+			return nil, fmt.Errorf("%s:%d: policy %q needs a nodes selector", name, item.line, p.Name)
+		}
+		if p.Allow == nil && p.Deny == nil {
+			return nil, fmt.Errorf("%s:%d: policy %q needs allow or deny", name, item.line, p.Name)
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+func parseSelector(name, key string, v *yamlValue) (Selector, error) {
+	if v.kind != yamlMap || len(v.keys) == 0 {
+		return Selector{}, fmt.Errorf("%s:%d: %s must be a mapping with path, id or tags", name, v.line, key)
+	}
+	var s Selector
+	for _, k := range v.keys {
+		values, err := yamlStrings(name, v.fields[k])
+		if err != nil {
+			return Selector{}, err
+		}
+		switch k {
+		case "path":
+			s.Paths = values
+		case "id":
+			s.IDs = values
+		case "tags":
+			s.Tags = values
+		default:
+			return Selector{}, fmt.Errorf("%s:%d: unknown selector %q in %s (use path, id, tags)", name, v.fields[k].line, k, key)
+		}
+	}
+	return s, nil
+}
+
+// yamlStrings accepts a scalar or a list of scalars.
+func yamlStrings(name string, v *yamlValue) ([]string, error) {
+	switch v.kind {
+	case yamlScalar:
+		if v.scalar == "" {
+			return nil, fmt.Errorf("%s:%d: expected a value", name, v.line)
+		}
+		return []string{v.scalar}, nil
+	case yamlList:
+		var out []string
+		for _, item := range v.items {
+			if item.kind != yamlScalar {
+				return nil, fmt.Errorf("%s:%d: expected a list of strings", name, item.line)
+			}
+			out = append(out, item.scalar)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%s:%d: expected a string or a list of strings", name, v.line)
+	}
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func TestPoliciesReportForbiddenDependencies(t *testing.T) {
+	cfg, err := parseConfig(ConfigFile, []byte(`policies:
+  - name: web must not touch migrations
+    nodes: {path: "web/**"}
+    deny:
+      path: migrations/**
+  - name: public-only
+    nodes:
+      tags: public
+    allow:
+      tags: [public]
+`))
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"page":    {ID: "page", File: "web/page.ts", Line: 2},
+			"migrate": {ID: "migrate", File: "migrations/001.sql", Line: 1},
+			"api":     {ID: "api", File: "api/api.go", Line: 4, Tags: []string{"public"}},
+			"util":    {ID: "util", File: "api/util.go", Line: 1, Tags: []string{"public"}},
+			"secret":  {ID: "secret", File: "api/secret.go", Line: 9},
+		},
+		Edges: []graph.Edge{
+			{From: "migrate", To: "page", Type: "blocks"},
+			{From: "util", To: "api", Type: "blocks"},
+			{From: "secret", To: "api", Type: "blocks"},
+			{From: "migrate", To: "util", Type: "blocks"},
+		},
+	}
+
+	report := ValidateGraphWithConfig(g, nil, cfg)
+	errs := report.RuleErrors()
+	if len(errs) != 3 {
+		t.Fatalf("expected three policy violations, got %+v", errs)
+	}
+	first := errs[0]
+	if first.Rule != RulePolicy || !strings.Contains(first.Message, `"page" (web/page.ts) must not depend on "migrate"`) {
+		t.Fatalf("unexpected first violation: %+v", first)
+	}
+	if len(first.Locations) != 2 || first.Locations[0].File != "web/page.ts" || first.Locations[1].File != "migrations/001.sql" {
+		t.Fatalf("expected both endpoints' locations, got %+v", first.Locations)
+	}
+	if errs[1].Nodes[0] != "util" || errs[2].Nodes[1] != "secret" || !strings.HasSuffix(errs[2].Message, "public-only") {
+		t.Fatalf("unexpected allow violations: %+v", errs[1:])
+	}
+}
+
+func TestSelectorMatchesNestedPaths(t *testing.T) {
+	s := Selector{Paths: []string{"web/**/handlers/*.go"}}
+	if !s.Match(graph.Node{ID: "h", File: filepath.Join("web", "app", "handlers", "user.go")}) {
+		t.Fatalf("expected nested OS path to match a slash-separated glob")
+	}
+	if s.Match(graph.Node{ID: "m", File: filepath.Join("api", "app", "handlers", "user.go")}) {
+		t.Fatalf("expected path outside web to be rejected")
+	}
+}
+
+func TestParsePoliciesErrors(t *testing.T) {
+	cases := []struct {
+		content string
+		want    string
+	}{
+		{"policies:\n  - deny: {path: a}\n", "needs a nodes selector"},
+		{"policies:\n  - nodes: {path: a}\n", "needs allow or deny"},
+		{"policies:\n  - nodes: {path: a}\n    deny:\n      owner: me\n", `unknown selector "owner"`},
+		{"policies:\n  - nodes: {path: a}\n    when: always\n", `unknown policy setting "when"`},
+	}
+	for _, tt := range cases {
+		_, err := parseConfig(ConfigFile, []byte(tt.content))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("expected error containing %q, got %v", tt.want, err)
+		}
+	}
+}
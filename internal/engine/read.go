@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,11 +13,21 @@ import (
 
 // ReadGraph parses the comment-graph.yml file from the repository root.
 func ReadGraph(root string) (graph.Graph, error) {
-	path := filepath.Join(root, "comment-graph.yml")
+	return ReadGraphFile(filepath.Join(root, "comment-graph.yml"))
+}
+
+// ReadGraphFile parses a stored graph from path: YAML as written by WriteGraph,
+// or (for .json files) JSON as written by WriteGraphJSON or printed by the
+// graph command.
+func ReadGraphFile(path string) (graph.Graph, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return graph.Graph{}, err
 	}
+	name := filepath.Base(path)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return parseGraphJSON(name, data)
+	}
 
 	lines := strings.Split(string(data), "\n")
 
@@ -74,7 +85,7 @@ func ReadGraph(root string) (graph.Graph, error) {
 				lineVal := strings.TrimSpace(strings.TrimPrefix(line, "line:"))
 				n, err := strconv.Atoi(lineVal)
 				if err != nil {
-					return graph.Graph{}, fmt.Errorf("comment-graph.yml:%d: invalid line number %q", i+1, lineVal)
+					return graph.Graph{}, fmt.Errorf("%s:%d: invalid line number %q", name, i+1, lineVal)
 				}
 				node := g.Nodes[currentID]
 				node.ID = currentID
@@ -93,6 +104,21 @@ func ReadGraph(root string) (graph.Graph, error) {
 				g.Nodes[currentID] = node
 				continue
 			}
+			if key, val, ok := strings.Cut(line, ":"); ok && (key == "tags" || key == "ignore") {
+				items, err := parseFlowList(strings.TrimSpace(val))
+				if err != nil {
+					return graph.Graph{}, fmt.Errorf("%s:%d: invalid %s list: %v", name, i+1, key, err)
+				}
+				node := g.Nodes[currentID]
+				node.ID = currentID
+				if key == "tags" {
+					node.Tags = items
+				} else {
+					node.Ignore = items
+				}
+				g.Nodes[currentID] = node
+				continue
+			}
 		case "edges":
 			if line == "[]" {
 				continue
@@ -142,3 +168,44 @@ func ReadGraph(root string) (graph.Graph, error) {
 
 	return g, nil
 }
+
+// parseFlowList parses "[a, b]" as written by writeNodes.
+func parseFlowList(val string) ([]string, error) {
+	if !strings.HasPrefix(val, "[") || !strings.HasSuffix(val, "]") {
+		return nil, fmt.Errorf("expected [a, b], got %q", val)
+	}
+	var items []string
+	for _, item := range strings.Split(val[1:len(val)-1], ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+type graphJSON struct {
+	Nodes map[string]graph.Node `json:"nodes"`
+	Edges []graph.Edge          `json:"edges"`
+}
+
+// parseGraphJSON accepts both the comment-graph.json shape and the graph
+// command's payload, which nests the graph under "graph".
+func parseGraphJSON(name string, data []byte) (graph.Graph, error) {
+	var doc struct {
+		graphJSON
+		Graph *graphJSON `json:"graph"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return graph.Graph{}, fmt.Errorf("%s: %w", name, err)
+	}
+	src := doc.graphJSON
+	if doc.Graph != nil {
+		src = *doc.Graph
+	}
+	g := graph.Graph{Nodes: make(map[string]graph.Node, len(src.Nodes)), Edges: src.Edges}
+	for id, n := range src.Nodes {
+		n.ID = id
+		g.Nodes[id] = n
+	}
+	return g, nil
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// findRedundantDeps reports blocks edges implied by a longer path: if c
+// depends on a and b, and b already depends on a, c's dependency on a is
+// redundant. Findings list the dependant, then the dependency. Edges touching
+// a cycle are left to the cycle rule, since removing them could disconnect
+// the nodes.
+func findRedundantDeps(g graph.Graph) []Finding {
+	blocks, blockedBy := adjacency(g)
+	ids := sortedNodeIDs(g)
+	cyclic := make(map[string]bool)
+	for _, scc := range stronglyConnected(ids, blocks, nil, nil) {
+		if len(scc) > 1 || slices.Contains(blocks[scc[0]], scc[0]) {
+			for _, id := range scc {
+				cyclic[id] = true
+			}
+		}
+	}
+
+	var out []Finding
+	for _, v := range ids {
+		deps := blockedBy[v]
+		if len(deps) < 2 || cyclic[v] {
+			continue
+		}
+		for _, u := range deps {
+			if cyclic[u] {
+				continue
+			}
+			path := pathThroughOtherDeps(blockedBy, v, u, deps)
+			if path == nil {
+				continue
+			}
+			out = append(out, Finding{
+				Message:    fmt.Sprintf("%q already depends on %q through %s", v, u, strings.Join(path, " -> ")),
+				Nodes:      []string{v, u},
+				Locations:  nodeLocations(g, []string{v, u}),
+				Suggestion: fmt.Sprintf("remove %q from the @cgraph-deps of %q", u, v),
+			})
+		}
+	}
+	return out
+}
+
+// pathThroughOtherDeps returns a path from u to v that reaches v through one
+// of its other dependencies, or nil if there is none.
+func pathThroughOtherDeps(blockedBy map[string][]string, v, u string, deps []string) []string {
+	// next records the step towards v from each node reached walking up
+	next := map[string]string{v: ""}
+	var stack []string
+	for _, d := range deps {
+		if d != u {
+			next[d] = v
+			stack = append(stack, d)
+		}
+	}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, p := range blockedBy[n] {
+			if _, seen := next[p]; seen {
+				continue
+			}
+			next[p] = n
+			if p != u {
+				stack = append(stack, p)
+				continue
+			}
+			path := []string{u}
+			for at := u; at != v; {
+				at = next[at]
+				path = append(path, at)
+			}
+			return path
+		}
+	}
+	return nil
+}
+
+// RedundantDeps returns the edges named by redundant-dep findings.
+func RedundantDeps(findings []Finding) []graph.Edge {
+	var edges []graph.Edge
+	for _, f := range findings {
+		if f.Rule == RuleRedundantDep && len(f.Nodes) == 2 {
+			edges = append(edges, graph.Edge{From: f.Nodes[1], To: f.Nodes[0], Type: "blocks"})
+		}
+	}
+	return edges
+}
+
+// RemoveDeps rewrites the @cgraph-deps lines of the dependants in edges
+// through UpdateDeps, dropping the given dependencies and keeping the rest in
+// their declared order. It returns the edges that were removed; nodes that
+// cannot be rewritten are reported in the error and left unchanged.
+func RemoveDeps(root string, g graph.Graph, edges []graph.Edge) ([]graph.Edge, error) {
+	drop := make(map[string][]string)
+	var order []string
+	for _, e := range edges {
+		if _, ok := drop[e.To]; !ok {
+			order = append(order, e.To)
+		}
+		drop[e.To] = append(drop[e.To], e.From)
+	}
+
+	var removed []graph.Edge
+	var errs []string
+	for _, id := range order {
+		var keep []string
+		for _, e := range g.Edges {
+			if e.To == id && !slices.Contains(drop[id], e.From) && !slices.Contains(keep, e.From) {
+				keep = append(keep, e.From)
+			}
+		}
+		if err := UpdateDeps(root, g, id, keep); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", id, err))
+			continue
+		}
+		for _, dep := range drop[id] {
+			removed = append(removed, graph.Edge{From: dep, To: id, Type: "blocks"})
+		}
+	}
+	if len(errs) > 0 {
+		return removed, fmt.Errorf("could not update %s", strings.Join(errs, "; "))
+	}
+	return removed, nil
+}
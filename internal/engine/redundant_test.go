@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindRedundantDeps(t *testing.T) {
+	g := cycleGraph([]string{"a", "b", "c", "d", "x", "y"},
+		// c depends on a and b; b depends on a
+		[2]string{"a", "b"}, [2]string{"a", "c"}, [2]string{"b", "c"},
+		// d depends on a and c, and c depends on a
+		[2]string{"a", "d"}, [2]string{"c", "d"},
+		// x and y depend on each other; neither edge is reported
+		[2]string{"x", "y"}, [2]string{"y", "x"}, [2]string{"x", "d"},
+	)
+
+	findings := findRedundantDeps(g)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Message)
+	}
+	want := []string{
+		`"c" already depends on "a" through a -> b -> c`,
+		`"d" already depends on "a" through a -> c -> d`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected findings:\n%s", strings.Join(got, "\n"))
+	}
+	if edges := RedundantDeps(ValidateGraph(g, nil).Findings); len(edges) != 2 || edges[0].From != "a" || edges[0].To != "c" {
+		t.Fatalf("unexpected redundant edges: %+v", edges)
+	}
+}
+
+func TestRemoveDepsRewritesDepsLines(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.ts", "// @cgraph-id a\n\n// @cgraph-id b\n// @cgraph-deps a\n\n")
+	writeFile(t, dir, "c.py", "# @cgraph-id c\n# @cgraph-deps a, b\n")
+
+	g, errs, err := Scan(dir)
+	if err != nil || len(errs) != 0 {
+		t.Fatalf("scan: %v %+v", err, errs)
+	}
+	removed, err := RemoveDeps(dir, g, RedundantDeps(ValidateGraph(g, nil).Findings))
+	if err != nil {
+		t.Fatalf("remove deps: %v", err)
+	}
+	if len(removed) != 1 || removed[0].From != "a" || removed[0].To != "c" {
+		t.Fatalf("unexpected removed edges: %+v", removed)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "c.py"))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(data) != "# @cgraph-id c\n# @cgraph-deps b\n" {
+		t.Fatalf("unexpected rewrite:\n%s", data)
+	}
+}
@@ -0,0 +1,112 @@
+package engine
+
+import "fmt"
+
+// Severity controls how a rule's findings are treated.
+type Severity string
+
+const (
+	// SeverityError findings fail validation.
+	SeverityError Severity = "error"
+	// SeverityWarn findings are reported without failing validation.
+	SeverityWarn Severity = "warn"
+	// SeverityOff disables a rule.
+	SeverityOff Severity = "off"
+)
+
+// ParseSeverity parses "error", "warn" (or "warning"), or "off".
+func ParseSeverity(s string) (Severity, error) {
+	switch s {
+	case "error":
+		return SeverityError, nil
+	case "warn", "warning":
+		return SeverityWarn, nil
+	case "off":
+		return SeverityOff, nil
+	default:
+		return "", fmt.Errorf("invalid severity %q (use error, warn, or off)", s)
+	}
+}
+
+// Rule IDs, as used in .comment-graph.yml and in findings.
+const (
+	RuleInvalidMetadata = "invalid-metadata"
+	RuleDuplicateID     = "duplicate-id"
+	RuleSkippedFile     = "skipped-file"
+	RuleUndefinedRef    = "undefined-ref"
+	RuleCycle           = "cycle"
+	RuleIsolated        = "isolated"
+	// RuleUnusedSuppression reports @cgraph-ignore entries that suppress nothing.
+	RuleUnusedSuppression = "unused-suppression"
+	// RulePolicy reports dependencies forbidden by a policy in .comment-graph.yml.
+	RulePolicy = "policy"
+	// Shape limits, enabled by the limits section of .comment-graph.yml.
+	RuleMaxDepth  = "max-depth"
+	RuleMaxFanIn  = "max-fan-in"
+	RuleMaxFanOut = "max-fan-out"
+	// RuleRedundantDep reports @cgraph-deps entries already implied by
+	// another dependency.
+	RuleRedundantDep = "redundant-dep"
+)
+
+// Rule describes a validation check and its default severity.
+type Rule struct {
+	ID          string
+	Severity    Severity
+	Description string
+	// Dedicated rules also report through their own CheckReport field
+	// (ScanErrors, UndefinedEdges, Cycles or Isolated), so RuleErrors leaves
+	// them out.
+	Dedicated bool
+}
+
+// Rules lists every check in the order findings are reported.
+var Rules = []Rule{
+	{ID: RuleInvalidMetadata, Severity: SeverityError, Description: "comment metadata that cannot be parsed", Dedicated: true},
+	{ID: RuleDuplicateID, Severity: SeverityError, Description: "the same @cgraph-id defined more than once", Dedicated: true},
+	{ID: RuleSkippedFile, Severity: SeverityWarn, Description: "files skipped by size limits or unfollowable symlinks", Dedicated: true},
+	{ID: RuleUndefinedRef, Severity: SeverityError, Description: "@cgraph-deps entries that reference unknown ids", Dedicated: true},
+	{ID: RuleCycle, Severity: SeverityError, Description: "dependency cycles", Dedicated: true},
+	{ID: RuleIsolated, Severity: SeverityError, Description: "nodes with no dependencies and no dependants", Dedicated: true},
+	{ID: RulePolicy, Severity: SeverityError, Description: "dependencies forbidden by a configured policy"},
+	{ID: RuleMaxDepth, Severity: SeverityError, Description: "dependency chains longer than limits.max-depth"},
+	{ID: RuleMaxFanIn, Severity: SeverityError, Description: "nodes with more dependencies than limits.max-fan-in"},
+	{ID: RuleMaxFanOut, Severity: SeverityError, Description: "nodes with more dependants than limits.max-fan-out"},
+	{ID: RuleRedundantDep, Severity: SeverityWarn, Description: "@cgraph-deps entries implied by another dependency (transitive reduction)"},
+	{ID: RuleUnusedSuppression, Severity: SeverityWarn, Description: "@cgraph-ignore entries that no longer suppress a finding"},
+}
+
+// suppressibleRules are the rules a node can silence with @cgraph-ignore.
+var suppressibleRules = []string{RuleUndefinedRef, RuleCycle, RuleIsolated, RulePolicy, RuleMaxDepth, RuleMaxFanIn, RuleMaxFanOut, RuleRedundantDep}
+
+// LookupRule returns the rule with the given ID.
+func LookupRule(id string) (Rule, bool) {
+	for _, r := range Rules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Location points at a place in the repository, optionally naming the node
+// defined there.
+type Location struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Node string `json:"node,omitempty"`
+}
+
+// Finding is a single rule violation with its effective severity. The first
+// location, if any, is the primary one. Code identifies the kind of violation;
+// see Codes.
+type Finding struct {
+	Rule      string     `json:"rule"`
+	Code      string     `json:"code"`
+	Severity  Severity   `json:"severity"`
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	Nodes     []string   `json:"nodes,omitempty"`
+	// Suggestion, if set, describes a change that resolves the finding.
+	Suggestion string `json:"suggestion,omitempty"`
+}
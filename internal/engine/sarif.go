@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"encoding/json"
+	"path/filepath"
+)
+
+// SARIF 2.1.0 documents, limited to the properties comment-graph fills in.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string        `json:"id"`
+	Name                 string        `json:"name"`
+	ShortDescription     sarifMessage  `json:"shortDescription"`
+	FullDescription      sarifMessage  `json:"fullDescription"`
+	Help                 sarifMessage  `json:"help"`
+	DefaultConfiguration sarifRuleConf `json:"defaultConfiguration"`
+}
+
+type sarifRuleConf struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	RuleIndex        *int            `json:"ruleIndex,omitempty"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               *int                   `json:"id,omitempty"`
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// RenderSARIF renders the report's findings as a SARIF 2.1.0 log. Every code
+// is listed as a rule; each finding's first location is its primary one and
+// the other nodes it involves, such as the rest of a cycle, are related
+// locations. Nodes without a location (the missing end of an undefined
+// reference) are related by name only. File URIs are relative to %SRCROOT%.
+func RenderSARIF(report CheckReport, toolVersion string) ([]byte, error) {
+	driver := sarifDriver{
+		Name:           "comment-graph",
+		InformationURI: "https://github.com/kuri-sun/comment-graph",
+		Version:        toolVersion,
+	}
+	index := make(map[string]int, len(Codes))
+	for i, c := range Codes {
+		index[c.ID] = i
+		level := "error"
+		if r, ok := LookupRule(c.Rule); ok {
+			level = sarifLevel(r.Severity)
+		}
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   c.ID,
+			Name:                 c.Rule,
+			ShortDescription:     sarifMessage{Text: c.Title},
+			FullDescription:      sarifMessage{Text: c.Description},
+			Help:                 sarifMessage{Text: c.Fix},
+			DefaultConfiguration: sarifRuleConf{Level: level},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, f := range report.Findings {
+		r := sarifResult{
+			RuleID:  f.Code,
+			Level:   sarifLevel(f.Severity),
+			Message: sarifMessage{Text: f.Message},
+		}
+		if i, ok := index[f.Code]; ok {
+			r.RuleIndex = &i
+		} else {
+			r.RuleID = f.Rule
+		}
+		located := make(map[string]bool)
+		for n, l := range f.Locations {
+			loc := sarifLocation{PhysicalLocation: sarifPhysical(l)}
+			if l.Node != "" {
+				located[l.Node] = true
+				loc.LogicalLocations = []sarifLogicalLocation{{Name: l.Node, Kind: "node"}}
+			}
+			if n == 0 {
+				r.Locations = append(r.Locations, loc)
+				continue
+			}
+			id := len(r.RelatedLocations) + 1
+			loc.ID = &id
+			loc.Message = &sarifMessage{Text: l.Node}
+			r.RelatedLocations = append(r.RelatedLocations, loc)
+		}
+		for _, node := range f.Nodes {
+			if located[node] {
+				continue
+			}
+			located[node] = true
+			id := len(r.RelatedLocations) + 1
+			r.RelatedLocations = append(r.RelatedLocations, sarifLocation{
+				ID:               &id,
+				LogicalLocations: []sarifLogicalLocation{{Name: node, Kind: "node"}},
+				Message:          &sarifMessage{Text: node + " (undefined)"},
+			})
+		}
+		results = append(results, r)
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	return json.MarshalIndent(log, "", "  ")
+}
+
+func sarifPhysical(l Location) *sarifPhysicalLocation {
+	p := &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(l.File), URIBaseID: "%SRCROOT%"}}
+	if l.Line > 0 {
+		p.Region = &sarifRegion{StartLine: l.Line}
+	}
+	return p
+}
+
+func sarifLevel(s Severity) string {
+	if s == SeverityWarn {
+		return "warning"
+	}
+	return "error"
+}
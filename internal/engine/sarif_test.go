@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func TestRenderSARIFRelatesEveryNode(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a": {ID: "a", File: "src/a.go", Line: 1},
+			"b": {ID: "b", File: "src/b.go", Line: 2},
+			"c": {ID: "c", File: "src/c.go", Line: 3},
+		},
+		Edges: []graph.Edge{
+			{From: "a", To: "b", Type: "blocks"},
+			{From: "b", To: "c", Type: "blocks"},
+			{From: "c", To: "a", Type: "blocks"},
+			{From: "missing", To: "a", Type: "blocks"},
+		},
+	}
+	errs := []ScanError{{File: "src/d.go", Line: 4, Msg: "metadata without @cgraph-id", Rule: RuleInvalidMetadata, Code: CodeMissingID}}
+	data, err := RenderSARIF(ValidateGraph(g, errs), "1.2.3")
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Version string `json:"version"`
+					Rules   []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine int }
+					}
+				}
+				RelatedLocations []struct {
+					LogicalLocations []struct{ Name string }
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("decode: %v\n%s", err, data)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Version != "1.2.3" {
+		t.Fatalf("unexpected log header: %s", data)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(Codes) {
+		t.Fatalf("expected %d rules, got %d", len(Codes), len(run.Tool.Driver.Rules))
+	}
+
+	related := make(map[string][]string)
+	for _, r := range run.Results {
+		if run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+			t.Fatalf("rule index %d does not point at %s", r.RuleIndex, r.RuleID)
+		}
+		for _, l := range r.RelatedLocations {
+			related[r.RuleID] = append(related[r.RuleID], l.LogicalLocations[0].Name)
+		}
+	}
+	if got := related[CodeCycle]; len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Fatalf("expected the rest of the cycle as related locations, got %v", got)
+	}
+	if got := related[CodeUndefinedRef]; len(got) != 1 || got[0] != "missing" {
+		t.Fatalf("expected the undefined node as a related location, got %v", got)
+	}
+	first := run.Results[0]
+	if first.RuleID != CodeMissingID || first.Locations[0].PhysicalLocation.ArtifactLocation.URI != "src/d.go" || first.Locations[0].PhysicalLocation.Region.StartLine != 4 {
+		t.Fatalf("expected the scan error first with its region, got %+v", first)
+	}
+}
@@ -1,13 +1,16 @@
 package engine
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/kuri-sun/comment-graph/internal/graph"
 )
@@ -19,71 +22,293 @@ var (
 
 var commentClosers = []string{"*/", "*/}", "-->", `"""`, `'''`}
 
+// metadataKeys are the @cgraph- keys a comment block may use.
+var metadataKeys = []string{"@cgraph-id", "@cgraph-deps", "@cgraph-label", "@cgraph-tags", "@cgraph-ignore"}
+
 // ScanError provides contextual information for parse failures.
+// Rule identifies the check it belongs to; its severity decides whether the
+// error fails validation. Code is the stable identifier of the error kind.
 type ScanError struct {
 	File string `json:"file"`
 	Line int    `json:"line"`
 	Msg  string `json:"msg"`
+	Rule string `json:"rule"`
+	Code string `json:"code"`
+}
+
+// Syntax names a comment style recognised by the scanner.
+type Syntax string
+
+const (
+	// SyntaxSlash is a C-family line comment: // ...
+	SyntaxSlash Syntax = "//"
+	// SyntaxHash is a shell/Python/Ruby/YAML line comment: # ...
+	SyntaxHash Syntax = "#"
+	// SyntaxDash is a SQL/Lua line comment: -- ...
+	SyntaxDash Syntax = "--"
+	// SyntaxBlock is a C-family block comment, including the JSX {/* */} form.
+	SyntaxBlock Syntax = "/*"
+	// SyntaxHTML is an HTML/Markdown comment: <!-- ... -->
+	SyntaxHTML Syntax = "<!--"
+	// SyntaxDocstring is a Python-style docstring using """ or '''.
+	SyntaxDocstring Syntax = `"""`
+)
+
+// ScanOptions tune a scan. The zero value scans every file with all comment
+// syntaxes, one file at a time.
+type ScanOptions struct {
+	// Ignore lists slash-separated glob patterns for files and directories to
+	// skip. Patterns containing "/" match the path relative to the scan root and
+	// may use "**" for any number of directories; other patterns match a base
+	// name at any depth.
+	Ignore []string
+	// Syntaxes restricts the comment styles that may carry metadata. Empty
+	// means all supported styles.
+	Syntaxes []Syntax
+	// Concurrency is the number of files parsed in parallel; values below 2
+	// parse sequentially.
+	Concurrency int
+	// FollowSymlinks scans symlinked files and directories instead of skipping
+	// them. Links must resolve inside fsys, which must implement fs.ReadLinkFS;
+	// loops and links that are broken or point outside the tree are skipped
+	// with a warning. A file reachable through several paths is scanned once,
+	// under the first path in walk order.
+	FollowSymlinks bool
+	// MaxFileSize skips larger files with a warning. Zero means
+	// DefaultMaxFileSize; a negative value disables the limit.
+	MaxFileSize int64
+	// MaxLineLength skips files containing a longer line, such as minified
+	// bundles, with a warning. Zero means DefaultMaxLineLength; a negative value
+	// disables the limit.
+	MaxLineLength int
+	// Overlay replaces file contents, keyed by slash-separated path relative to
+	// the scan root. Editors use it to scan unsaved buffers; entries for files
+	// that do not exist yet are scanned as new files.
+	Overlay map[string][]byte
+}
+
+func (o ScanOptions) ignored(name string) bool {
+	for _, pattern := range o.Ignore {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// syntaxSet reports which comment syntaxes are enabled; nil enables all.
+type syntaxSet map[Syntax]bool
+
+func (o ScanOptions) syntaxSet() syntaxSet {
+	if len(o.Syntaxes) == 0 {
+		return nil
+	}
+	set := make(syntaxSet, len(o.Syntaxes))
+	for _, s := range o.Syntaxes {
+		set[s] = true
+	}
+	return set
+}
+
+func (s syntaxSet) has(syntax Syntax) bool {
+	return s == nil || s[syntax]
 }
 
 // Scan walks the repository and builds a comment graph.
 func Scan(root string) (graph.Graph, []ScanError, error) {
-	nodes := make(map[string]graph.Node)
-	var edges []graph.Edge
-	var errs []ScanError
+	return ScanFS(context.Background(), os.DirFS(root), ScanOptions{})
+}
 
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return walkErr
-		}
-		if d.IsDir() && shouldSkipDir(d.Name()) {
-			return filepath.SkipDir
-		}
-		if d.IsDir() {
-			return nil
-		}
-		if d.Name() == ".comment-graph" || d.Name() == "comment-graph.yml" || d.Name() == "comment-graph.json" {
-			return nil
-		}
-		if d.Type()&fs.ModeSymlink != 0 {
-			return nil
-		}
+// ScanFS walks fsys from its root and builds a comment graph. File paths in
+// the result use the OS separator, like Scan. The scan stops early with the
+// context's error if ctx is cancelled.
+func ScanFS(ctx context.Context, fsys fs.FS, opts ScanOptions) (graph.Graph, []ScanError, error) {
+	files, warnings, err := listFiles(ctx, fsys, opts)
+	if err != nil {
+		return graph.Graph{}, nil, err
+	}
+
+	syntaxes := opts.syntaxSet()
+	results := make([]fileResult, len(files))
+	err = forEach(ctx, len(files), opts.Concurrency, func(i int) error {
+		var err error
+		results[i], err = parseFSFile(fsys, files[i], opts, syntaxes)
+		return err
+	})
+	if err != nil {
+		return graph.Graph{}, nil, err
+	}
+
+	c := newCollector()
+	c.errs = append(c.errs, warnings...)
+	for i, name := range files {
+		c.add(filepath.FromSlash(name), results[i])
+	}
+	g, errs := c.result()
+	return g, errs, nil
+}
 
-		rel, err := filepath.Rel(root, path)
-		if err != nil {
-			return err
+// addOverlayFiles merges overlay entries that were not found on disk into
+// files, keeping walk order.
+func addOverlayFiles(files []string, opts ScanOptions) []string {
+	if len(opts.Overlay) == 0 {
+		return files
+	}
+	seen := make(map[string]bool, len(files))
+	for _, f := range files {
+		seen[f] = true
+	}
+	added := false
+	for name := range opts.Overlay {
+		if seen[name] || skipPath(name, opts) {
+			continue
 		}
-		fileEdges, fileNodes, fileErrs, err := scanFile(path, rel)
-		if err != nil {
-			return err
+		files = append(files, name)
+		added = true
+	}
+	if added {
+		sort.Slice(files, func(i, j int) bool { return walkLess(files[i], files[j]) })
+	}
+	return files
+}
+
+// skipPath applies the directory, file and ignore rules used while walking to
+// a slash-separated path that was not reached by a walk.
+func skipPath(name string, opts ScanOptions) bool {
+	parts := strings.Split(name, "/")
+	for i, dir := range parts[:len(parts)-1] {
+		if shouldSkipDir(dir) || opts.ignored(strings.Join(parts[:i+1], "/")) {
+			return true
 		}
-		for _, e := range fileErrs {
-			errs = append(errs, e)
+	}
+	return isGraphFile(parts[len(parts)-1]) || opts.ignored(name)
+}
+
+// walkLess orders slash-separated paths component by component, which is the
+// order fs.WalkDir visits them in.
+func walkLess(a, b string) bool {
+	ap := strings.Split(a, "/")
+	bp := strings.Split(b, "/")
+	for i := 0; i < len(ap) && i < len(bp); i++ {
+		if ap[i] != bp[i] {
+			return ap[i] < bp[i]
 		}
-		for _, n := range fileNodes {
-			if existing, ok := nodes[n.ID]; ok {
-				errs = append(errs, ScanError{
-					File: rel,
-					Line: n.Line,
-					Msg:  fmt.Sprintf("duplicate comment-graph id %q (first defined in %s:%d)", n.ID, existing.File, existing.Line),
-				})
-				continue
+	}
+	return len(ap) < len(bp)
+}
+
+// forEach calls fn for every index in [0, n) using up to workers goroutines.
+// It returns the first error from fn, or the context's error once ctx is done.
+func forEach(ctx context.Context, n, workers int, fn func(int) error) error {
+	if workers < 2 {
+		for i := 0; i < n; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(i); err != nil {
+				return err
 			}
-			nodes[n.ID] = n
 		}
-		edges = append(edges, fileEdges...)
 		return nil
-	})
-	if err != nil {
-		return graph.Graph{}, nil, err
 	}
 
-	edges = dedupeEdges(edges)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	indexes := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(i); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// fileResult holds what parseContent found in a single file.
+type fileResult struct {
+	edges []graph.Edge
+	nodes []graph.Node
+	errs  []ScanError
+}
+
+// collector merges per-file scan results into a single graph, reporting
+// duplicate ids in the order files were added.
+type collector struct {
+	nodes map[string]graph.Node
+	edges []graph.Edge
+	errs  []ScanError
+}
+
+func newCollector() *collector {
+	return &collector{nodes: make(map[string]graph.Node)}
+}
+
+func (c *collector) add(rel string, r fileResult) {
+	c.errs = append(c.errs, r.errs...)
+	for _, n := range r.nodes {
+		if existing, ok := c.nodes[n.ID]; ok {
+			c.errs = append(c.errs, ScanError{
+				File: rel,
+				Line: n.Line,
+				Msg:  fmt.Sprintf("duplicate comment-graph id %q (first defined in %s:%d)", n.ID, existing.File, existing.Line),
+				Rule: RuleDuplicateID,
+				Code: CodeDuplicateID,
+			})
+			continue
+		}
+		c.nodes[n.ID] = n
+	}
+	c.edges = append(c.edges, r.edges...)
+}
 
+func (c *collector) result() (graph.Graph, []ScanError) {
 	return graph.Graph{
-		Nodes: nodes,
-		Edges: edges,
-	}, errs, nil
+		Nodes: c.nodes,
+		Edges: dedupeEdges(c.edges),
+	}, c.errs
+}
+
+// isGraphFile reports whether name is a file managed by comment-graph itself.
+func isGraphFile(name string) bool {
+	switch name {
+	case ".comment-graph", ConfigFile, DefaultBaselineFile, "comment-graph.yml", "comment-graph.json":
+		return true
+	default:
+		return false
+	}
 }
 
 func shouldSkipDir(name string) bool {
@@ -95,176 +320,208 @@ func shouldSkipDir(name string) bool {
 	}
 }
 
-func scanFile(path, rel string) ([]graph.Edge, []graph.Node, []ScanError, error) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-	if isBinary(content) {
-		return nil, nil, nil, nil
-	}
+// lineParser extracts nodes and edges from a file one line at a time.
+type lineParser struct {
+	rel      string
+	syntaxes syntaxSet
 
-	lines := strings.Split(string(content), "\n")
+	edges   []graph.Edge
+	errs    []ScanError
+	nodes   map[string]graph.Node
+	current *pendingNode
 
-	var edges []graph.Edge
-	var errs []ScanError
-	nodes := make(map[string]graph.Node)
+	inBlock  bool
+	blockEnd string
+}
 
-	type pending struct {
-		line    int
-		id      string
-		deps    []string
-		label   string
-		invalid bool
-		hasMeta bool
-	}
-	var current *pending
+// pendingNode accumulates the metadata of the comment block being parsed.
+type pendingNode struct {
+	line    int
+	id      string
+	deps    []string
+	label   string
+	tags    []string
+	ignore  []string
+	invalid bool
+	hasMeta bool
+}
 
-	flush := func() {
-		if current == nil {
-			return
-		}
-		if current.invalid {
-			current = nil
-			return
-		}
-		if current.id == "" {
-			if current.hasMeta {
-				errs = append(errs, ScanError{File: rel, Line: current.line, Msg: "metadata without @cgraph-id"})
-			}
-			current = nil
-			return
-		}
-		nodes[current.id] = graph.Node{ID: current.id, File: rel, Line: current.line, Label: current.label}
-		for _, dep := range current.deps {
-			edges = append(edges, graph.Edge{From: dep, To: current.id, Type: "blocks"})
+func newLineParser(rel string, syntaxes syntaxSet) *lineParser {
+	return &lineParser{rel: rel, syntaxes: syntaxes, nodes: make(map[string]graph.Node)}
+}
+
+func (p *lineParser) flush() {
+	current := p.current
+	if current == nil {
+		return
+	}
+	p.current = nil
+	if current.invalid {
+		return
+	}
+	if current.id == "" {
+		if current.hasMeta {
+			p.errs = append(p.errs, ScanError{File: p.rel, Line: current.line, Msg: "metadata without @cgraph-id", Code: CodeMissingID})
 		}
-		current = nil
+		return
 	}
+	p.nodes[current.id] = graph.Node{ID: current.id, File: p.rel, Line: current.line, Label: current.label, Tags: current.tags, Ignore: current.ignore}
+	for _, dep := range current.deps {
+		p.edges = append(p.edges, graph.Edge{From: dep, To: current.id, Type: "blocks"})
+	}
+}
 
-	inBlock := false
-	blockEnd := ""
+// feed parses line, the 1-based lineNo-th line of the file.
+func (p *lineParser) feed(lineNo int, line string) {
+	syntaxes := p.syntaxes
+	trimmed := strings.TrimSpace(line)
 
-	for i := 0; i < len(lines); i++ {
-		line := lines[i]
-		trimmed := strings.TrimSpace(line)
+	blockStart := syntaxes.has(SyntaxBlock) && (strings.HasPrefix(trimmed, "/*") || strings.HasPrefix(trimmed, "{/*"))
+	htmlStart := syntaxes.has(SyntaxHTML) && strings.HasPrefix(trimmed, "<!--")
+	docStart := syntaxes.has(SyntaxDocstring) && (strings.HasPrefix(trimmed, `"""`) || strings.HasPrefix(trimmed, `'''`))
 
-		isCommentStart := strings.HasPrefix(trimmed, "//") ||
-			strings.HasPrefix(trimmed, "#") ||
-			strings.HasPrefix(trimmed, "--") ||
-			strings.HasPrefix(trimmed, "/*") ||
-			strings.HasPrefix(trimmed, "{/*") ||
-			strings.HasPrefix(trimmed, "<!--") ||
-			strings.HasPrefix(trimmed, `"""`) ||
-			strings.HasPrefix(trimmed, `'''`) ||
-			(inBlock && strings.HasPrefix(trimmed, "*"))
+	isCommentStart := (syntaxes.has(SyntaxSlash) && strings.HasPrefix(trimmed, "//")) ||
+		(syntaxes.has(SyntaxHash) && strings.HasPrefix(trimmed, "#")) ||
+		(syntaxes.has(SyntaxDash) && strings.HasPrefix(trimmed, "--")) ||
+		blockStart || htmlStart || docStart ||
+		(p.inBlock && strings.HasPrefix(trimmed, "*"))
 
-		comment := inBlock || isCommentStart
+	comment := p.inBlock || isCommentStart
 
-		if inBlock && blockEnd != "" && strings.Contains(line, blockEnd) {
-			inBlock = false
-			blockEnd = ""
-		}
+	if p.inBlock && p.blockEnd != "" && strings.Contains(line, p.blockEnd) {
+		p.inBlock = false
+		p.blockEnd = ""
+	}
 
-		if !inBlock {
-			switch {
-			case strings.HasPrefix(trimmed, "/*") || strings.HasPrefix(trimmed, "{/*"):
-				if !strings.Contains(line, "*/") && !strings.Contains(line, "*/}") {
-					inBlock = true
-					blockEnd = "*/"
-					if strings.HasPrefix(trimmed, "{/*") {
-						blockEnd = "*/}"
-					}
-				}
-			case strings.HasPrefix(trimmed, "<!--"):
-				if !strings.Contains(line, "-->") {
-					inBlock = true
-					blockEnd = "-->"
-				}
-			case strings.HasPrefix(trimmed, `"""`):
-				if strings.Count(line, `"""`) == 1 {
-					inBlock = true
-					blockEnd = `"""`
-				}
-			case strings.HasPrefix(trimmed, `'''`):
-				if strings.Count(line, `'''`) == 1 {
-					inBlock = true
-					blockEnd = `'''`
+	if !p.inBlock {
+		switch {
+		case blockStart:
+			if !strings.Contains(line, "*/") && !strings.Contains(line, "*/}") {
+				p.inBlock = true
+				p.blockEnd = "*/"
+				if strings.HasPrefix(trimmed, "{/*") {
+					p.blockEnd = "*/}"
 				}
 			}
+		case htmlStart:
+			if !strings.Contains(line, "-->") {
+				p.inBlock = true
+				p.blockEnd = "-->"
+			}
+		case docStart && strings.HasPrefix(trimmed, `"""`):
+			if strings.Count(line, `"""`) == 1 {
+				p.inBlock = true
+				p.blockEnd = `"""`
+			}
+		case docStart:
+			if strings.Count(line, `'''`) == 1 {
+				p.inBlock = true
+				p.blockEnd = `'''`
+			}
 		}
+	}
 
-		if current != nil && (trimmed == "" || !comment) {
-			flush()
-		}
+	if p.current != nil && (trimmed == "" || !comment) {
+		p.flush()
+	}
 
-		if !comment {
-			continue
-		}
+	if !comment {
+		return
+	}
 
-		cleaned := strings.TrimSpace(commentLine.ReplaceAllString(line, ""))
-		lower := strings.ToLower(cleaned)
+	cleaned := strings.TrimSpace(commentLine.ReplaceAllString(line, ""))
+	lower := strings.ToLower(cleaned)
 
-		switch {
-		case strings.HasPrefix(lower, "@cgraph-id"):
-			if current != nil {
-				flush()
-			}
-			if current == nil {
-				current = &pending{}
-			}
-			current.hasMeta = true
-			val := strings.TrimSpace(strings.TrimPrefix(cleaned, "@cgraph-id"))
-			val = strings.TrimSpace(cleanCommentSuffix(val))
-			if val == "" {
-				errs = append(errs, ScanError{File: rel, Line: i + 1, Msg: "@cgraph-id must not be empty"})
-				current.invalid = true
-				continue
-			}
-			if !cgraphIDPattern.MatchString(val) {
-				errs = append(errs, ScanError{
-					File: rel,
-					Line: i + 1,
-					Msg:  fmt.Sprintf("@cgraph-id %q must use lowercase letters, digits, hyphens, or underscores", val),
-				})
-				current.invalid = true
-				continue
-			}
-			current.id = val
-			current.line = i + 1
-		case strings.HasPrefix(lower, "@cgraph-deps"):
-			if current == nil {
-				current = &pending{line: i + 1}
+	switch {
+	case strings.HasPrefix(lower, "@cgraph-id"):
+		if p.current != nil {
+			p.flush()
+		}
+		p.current = &pendingNode{hasMeta: true}
+		val := strings.TrimSpace(strings.TrimPrefix(cleaned, "@cgraph-id"))
+		val = strings.TrimSpace(cleanCommentSuffix(val))
+		if val == "" {
+			p.errs = append(p.errs, ScanError{File: p.rel, Line: lineNo, Msg: "@cgraph-id must not be empty", Code: CodeEmptyID})
+			p.current.invalid = true
+			return
+		}
+		if !cgraphIDPattern.MatchString(val) {
+			p.errs = append(p.errs, ScanError{
+				File: p.rel,
+				Line: lineNo,
+				Msg:  fmt.Sprintf("@cgraph-id %q must use lowercase letters, digits, hyphens, or underscores", val),
+				Code: CodeInvalidID,
+			})
+			p.current.invalid = true
+			return
+		}
+		p.current.id = val
+		p.current.line = lineNo
+	case strings.HasPrefix(lower, "@cgraph-deps"):
+		if p.current == nil {
+			p.current = &pendingNode{line: lineNo}
+		}
+		p.current.hasMeta = true
+		raw := strings.TrimSpace(strings.TrimPrefix(cleaned, "@cgraph-deps"))
+		raw = strings.TrimSpace(cleanCommentSuffix(raw))
+		ids, idErrs := parseIDs(raw, lineNo, p.rel)
+		p.errs = append(p.errs, idErrs...)
+		p.current.deps = append(p.current.deps, ids...)
+	case strings.HasPrefix(lower, "@cgraph-label"):
+		if p.current == nil {
+			p.current = &pendingNode{line: lineNo}
+		}
+		p.current.hasMeta = true
+		val := strings.TrimSpace(strings.TrimPrefix(cleaned, "@cgraph-label"))
+		val = strings.TrimSpace(cleanCommentSuffix(val))
+		p.current.label = val
+	case strings.HasPrefix(lower, "@cgraph-tags"):
+		if p.current == nil {
+			p.current = &pendingNode{line: lineNo}
+		}
+		p.current.hasMeta = true
+		raw := strings.TrimSpace(strings.TrimPrefix(cleaned, "@cgraph-tags"))
+		raw = strings.TrimSpace(cleanCommentSuffix(raw))
+		tags, tagErrs := parseIDs(raw, lineNo, p.rel)
+		p.errs = append(p.errs, tagErrs...)
+		for _, tag := range tags {
+			if !slices.Contains(p.current.tags, tag) {
+				p.current.tags = append(p.current.tags, tag)
 			}
-			current.hasMeta = true
-			raw := strings.TrimSpace(strings.TrimPrefix(cleaned, "@cgraph-deps"))
-			raw = strings.TrimSpace(cleanCommentSuffix(raw))
-			ids, idErrs := parseIDs(raw, i+1, rel)
-			errs = append(errs, idErrs...)
-			current.deps = append(current.deps, ids...)
-		case strings.HasPrefix(lower, "@cgraph-label"):
-			if current == nil {
-				current = &pending{line: i + 1}
+		}
+	case strings.HasPrefix(lower, "@cgraph-ignore"):
+		if p.current == nil {
+			p.current = &pendingNode{line: lineNo}
+		}
+		p.current.hasMeta = true
+		raw := strings.TrimSpace(strings.TrimPrefix(cleaned, "@cgraph-ignore"))
+		raw = strings.TrimSpace(cleanCommentSuffix(raw))
+		rules, ruleErrs := parseIgnoredRules(raw, lineNo, p.rel)
+		p.errs = append(p.errs, ruleErrs...)
+		for _, rule := range rules {
+			if !slices.Contains(p.current.ignore, rule) {
+				p.current.ignore = append(p.current.ignore, rule)
 			}
-			current.hasMeta = true
-			val := strings.TrimSpace(strings.TrimPrefix(cleaned, "@cgraph-label"))
-			val = strings.TrimSpace(cleanCommentSuffix(val))
-			current.label = val
-		case strings.HasPrefix(lower, "@"):
-			errs = append(errs, ScanError{File: rel, Line: i + 1, Msg: "unknown metadata (use @cgraph-id or @cgraph-deps)"})
-		default:
-			// plain comment line, keep association
 		}
+	case strings.HasPrefix(lower, "@"):
+		p.errs = append(p.errs, ScanError{File: p.rel, Line: lineNo, Msg: "unknown metadata (use " + strings.Join(metadataKeys, ", ") + ")", Code: CodeUnknownMetadata})
+	default:
+		// plain comment line, keep association
 	}
+}
 
-	flush()
+// finish flushes the last comment block and returns what the file defined.
+func (p *lineParser) finish() fileResult {
+	p.flush()
+	for i := range p.errs {
+		p.errs[i].Rule = RuleInvalidMetadata
+	}
 
 	var nodeList []graph.Node
-	for _, n := range nodes {
+	for _, n := range p.nodes {
 		nodeList = append(nodeList, n)
 	}
-
-	return edges, nodeList, errs, nil
+	return fileResult{edges: p.edges, nodes: nodeList, errs: p.errs}
 }
 
 func cleanCommentSuffix(s string) string {
@@ -275,6 +532,32 @@ func cleanCommentSuffix(s string) string {
 	return strings.TrimSpace(s)
 }
 
+// parseIgnoredRules parses the comma-separated rule IDs of @cgraph-ignore.
+func parseIgnoredRules(raw string, line int, file string) ([]string, []ScanError) {
+	if raw == "" {
+		return nil, []ScanError{{File: file, Line: line, Msg: "@cgraph-ignore needs a rule (" + strings.Join(suppressibleRules, ", ") + ")", Code: CodeInvalidIgnore}}
+	}
+	var rules []string
+	var errs []ScanError
+	for _, part := range strings.Split(raw, ",") {
+		rule := strings.TrimSpace(part)
+		switch {
+		case slices.Contains(suppressibleRules, rule):
+			rules = append(rules, rule)
+		case rule == "":
+			errs = append(errs, ScanError{File: file, Line: line, Msg: "@cgraph-ignore contains an empty rule", Code: CodeInvalidIgnore})
+		default:
+			errs = append(errs, ScanError{
+				File: file,
+				Line: line,
+				Msg:  fmt.Sprintf("@cgraph-ignore: rule %q cannot be suppressed (use %s)", rule, strings.Join(suppressibleRules, ", ")),
+				Code: CodeInvalidIgnore,
+			})
+		}
+	}
+	return rules, errs
+}
+
 func parseIDs(raw string, line int, file string) ([]string, []ScanError) {
 	if raw == "" {
 		return nil, nil
@@ -285,7 +568,7 @@ func parseIDs(raw string, line int, file string) ([]string, []ScanError) {
 	}
 
 	if !strings.Contains(trimmed, ",") && strings.Contains(trimmed, " ") {
-		return nil, []ScanError{{File: file, Line: line, Msg: "ids must be comma-separated (e.g. a, b)"}}
+		return nil, []ScanError{{File: file, Line: line, Msg: "ids must be comma-separated (e.g. a, b)", Code: CodeIDList}}
 	}
 
 	parts := strings.Split(trimmed, ",")
@@ -298,7 +581,7 @@ func parseIDs(raw string, line int, file string) ([]string, []ScanError) {
 			continue
 		}
 		if strings.Contains(p, " ") {
-			errs = append(errs, ScanError{File: file, Line: line, Msg: "ids must be comma-separated (e.g. a, b)"})
+			errs = append(errs, ScanError{File: file, Line: line, Msg: "ids must be comma-separated (e.g. a, b)", Code: CodeIDList})
 			continue
 		}
 		if !cgraphIDPattern.MatchString(p) {
@@ -306,6 +589,7 @@ func parseIDs(raw string, line int, file string) ([]string, []ScanError) {
 				File: file,
 				Line: line,
 				Msg:  fmt.Sprintf("id %q must use lowercase letters, digits, hyphens, or underscores", p),
+				Code: CodeInvalidID,
 			})
 			continue
 		}
@@ -334,13 +618,3 @@ func dedupeEdges(edges []graph.Edge) []graph.Edge {
 	})
 	return out
 }
-
-func isBinary(content []byte) bool {
-	// simple heuristic: treat files with NULL bytes as binary
-	for _, b := range content {
-		if b == 0 {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// ScanFiles re-parses only files (slash-separated paths relative to the root
+// of fsys) and merges the result into base, typically the graph stored in
+// comment-graph.yml. Nodes and edges that base attributes to one of files are
+// replaced by what the file defines now; files that no longer exist or are
+// skipped by opts contribute nothing, so their nodes are dropped. Every other
+// node and edge in base is kept as is.
+func ScanFiles(ctx context.Context, fsys fs.FS, base graph.Graph, files []string, opts ScanOptions) (graph.Graph, []ScanError, error) {
+	rescanned := make(map[string]bool, len(files))
+	var names []string
+	for _, f := range files {
+		name := filepath.ToSlash(filepath.Clean(f))
+		if rescanned[name] {
+			continue
+		}
+		rescanned[name] = true
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return walkLess(names[i], names[j]) })
+
+	syntaxes := opts.syntaxSet()
+	results := make([]fileResult, len(names))
+	err := forEach(ctx, len(names), opts.Concurrency, func(i int) error {
+		if skipPath(names[i], opts) {
+			return nil
+		}
+		r, err := parseFSFile(fsys, names[i], opts, syntaxes)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		results[i] = r
+		return err
+	})
+	if err != nil {
+		return graph.Graph{}, nil, err
+	}
+
+	c := newCollector()
+	kept := make(map[string]bool, len(base.Nodes))
+	for id, n := range base.Nodes {
+		if rescanned[filepath.ToSlash(n.File)] {
+			continue
+		}
+		c.nodes[id] = n
+		kept[id] = true
+	}
+	// edges are declared by the @cgraph-deps of their "to" node
+	for _, e := range base.Edges {
+		if kept[e.To] {
+			c.edges = append(c.edges, e)
+		}
+	}
+	for i, name := range names {
+		c.add(filepath.FromSlash(name), results[i])
+	}
+
+	g, errs := c.result()
+	return g, errs, nil
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func TestScanFilesMergesIntoBase(t *testing.T) {
+	base := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a": {ID: "a", File: "a.go", Line: 1},
+			"b": {ID: "b", File: "b.go", Line: 1},
+			"c": {ID: "c", File: "c.go", Line: 1},
+			"d": {ID: "d", File: "b.go", Line: 5},
+		},
+		Edges: []graph.Edge{
+			{From: "a", To: "b", Type: "blocks"},
+			{From: "a", To: "c", Type: "blocks"},
+			{From: "b", To: "d", Type: "blocks"},
+		},
+	}
+	// b.go no longer defines d and now depends on c; c.go was deleted.
+	fsys := fstest.MapFS{
+		"a.go":     {Data: []byte("// @cgraph-id a\n")},
+		"b.go":     {Data: []byte("// @cgraph-id b\n// @cgraph-deps c\n")},
+		"new/e.go": {Data: []byte("// @cgraph-id e\n// @cgraph-deps b\n")},
+	}
+
+	g, errs, err := ScanFiles(context.Background(), fsys, base, []string{"b.go", "c.go", "./new/e.go"}, ScanOptions{})
+	if err != nil {
+		t.Fatalf("scan files: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("unexpected scan errors: %+v", errs)
+	}
+	for _, id := range []string{"a", "b", "e"} {
+		if _, ok := g.Nodes[id]; !ok {
+			t.Fatalf("missing node %s: %+v", id, g.Nodes)
+		}
+	}
+	if len(g.Nodes) != 3 {
+		t.Fatalf("expected c and d to be dropped, got %+v", g.Nodes)
+	}
+	want := []string{"b->e", "c->b"}
+	if len(g.Edges) != len(want) {
+		t.Fatalf("unexpected edges: %+v", g.Edges)
+	}
+	for i, e := range g.Edges {
+		if e.From+"->"+e.To != want[i] {
+			t.Fatalf("unexpected edges: %+v", g.Edges)
+		}
+	}
+
+	report := ValidateGraph(g, errs)
+	if len(report.UndefinedEdges) != 1 || report.UndefinedEdges[0].From != "c" {
+		t.Fatalf("expected dependency on deleted node to be undefined, got %+v", report.UndefinedEdges)
+	}
+}
+
+func TestScanFilesReportsDuplicateAgainstBase(t *testing.T) {
+	base := graph.Graph{
+		Nodes: map[string]graph.Node{"a": {ID: "a", File: "a.go", Line: 3}},
+	}
+	fsys := fstest.MapFS{"b.go": {Data: []byte("// @cgraph-id a\n")}}
+
+	_, errs, err := ScanFiles(context.Background(), fsys, base, []string{"b.go"}, ScanOptions{})
+	if err != nil {
+		t.Fatalf("scan files: %v", err)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Msg, "first defined in a.go:3") {
+		t.Fatalf("expected duplicate against base, got %+v", errs)
+	}
+}
@@ -0,0 +1,202 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// ScanRev builds a comment graph from the tree of a git revision.
+// Files are read from the local object store, so the working tree is neither
+// consulted nor modified. Only the part of the tree under root is scanned and
+// paths are reported relative to root, matching Scan. Ignore patterns and
+// syntaxes in opts apply as they do for ScanFS; Overlay and FollowSymlinks
+// are not used.
+func ScanRev(ctx context.Context, root, rev string, opts ScanOptions) (graph.Graph, []ScanError, error) {
+	if rev == "" || strings.HasPrefix(rev, "-") {
+		return graph.Graph{}, nil, fmt.Errorf("invalid revision %q", rev)
+	}
+
+	out, err := runGit(ctx, root, "rev-parse", "--verify", "--quiet", rev+"^{tree}")
+	if err != nil {
+		return graph.Graph{}, nil, fmt.Errorf("unknown revision %q", rev)
+	}
+	tree := strings.TrimSpace(string(out))
+
+	entries, err := listTree(ctx, root, tree, opts)
+	if err != nil {
+		return graph.Graph{}, nil, err
+	}
+
+	blobs, err := openBlobReader(ctx, root)
+	if err != nil {
+		return graph.Graph{}, nil, err
+	}
+	defer blobs.close()
+
+	syntaxes := opts.syntaxSet()
+	c := newCollector()
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return graph.Graph{}, nil, err
+		}
+		if max := opts.maxFileSize(); max > 0 && e.size > max {
+			c.add(e.rel, skippedFile(e.rel, fmt.Sprintf("skipped file: %d bytes exceeds the %d byte limit", e.size, max)))
+			continue
+		}
+		blob, err := blobs.open(e.object)
+		if err != nil {
+			return graph.Graph{}, nil, fmt.Errorf("%s: %w", e.rel, err)
+		}
+		r, err := parseReader(blob, e.size, e.rel, opts, syntaxes)
+		if err != nil {
+			return graph.Graph{}, nil, fmt.Errorf("%s: %w", e.rel, err)
+		}
+		if err := blobs.done(blob); err != nil {
+			return graph.Graph{}, nil, fmt.Errorf("%s: %w", e.rel, err)
+		}
+		c.add(e.rel, r)
+	}
+
+	g, errs := c.result()
+	return g, errs, nil
+}
+
+type treeEntry struct {
+	object string
+	size   int64
+	rel    string
+}
+
+// listTree returns the regular files of tree below dir, skipping the same
+// directories and files as Scan. Entries are ordered like fs.WalkDir so
+// duplicate ids are reported against the same "first" definition.
+func listTree(ctx context.Context, dir, tree string, opts ScanOptions) ([]treeEntry, error) {
+	out, err := runGit(ctx, dir, "ls-tree", "-r", "-z", "-l", tree)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []treeEntry
+	for _, record := range strings.Split(string(out), "\x00") {
+		if record == "" {
+			continue
+		}
+		meta, name, ok := strings.Cut(record, "\t")
+		if !ok {
+			return nil, fmt.Errorf("unexpected ls-tree output %q", record)
+		}
+		// "<mode> <type> <object> <size>", size is "-" for non-blobs
+		fields := strings.Fields(meta)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected ls-tree output %q", record)
+		}
+		mode, kind, object := fields[0], fields[1], fields[2]
+		// symlinks (120000) and submodules (160000) are skipped like in Scan
+		if kind != "blob" || (mode != "100644" && mode != "100755") {
+			continue
+		}
+		if skipPath(name, opts) {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected ls-tree output %q", record)
+		}
+		entries = append(entries, treeEntry{object: object, size: size, rel: filepath.FromSlash(name)})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return walkLess(filepath.ToSlash(entries[i].rel), filepath.ToSlash(entries[j].rel))
+	})
+	return entries, nil
+}
+
+// blobReader streams blob contents through a single `git cat-file --batch`.
+type blobReader struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Reader
+}
+
+func openBlobReader(ctx context.Context, dir string) (*blobReader, error) {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "--batch")
+	cmd.Dir = dir
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	return &blobReader{cmd: cmd, in: in, out: bufio.NewReader(out)}, nil
+}
+
+// open requests object and returns a reader over its contents, streamed
+// from the batch output. It must be passed to done before the next object is
+// opened.
+func (r *blobReader) open(object string) (*io.LimitedReader, error) {
+	if _, err := io.WriteString(r.in, object+"\n"); err != nil {
+		return nil, err
+	}
+	header, err := r.out.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	// header: "<object> <type> <size>" or "<object> missing"
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("git cat-file: object %s not found", object)
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: unexpected header %q", strings.TrimSpace(header))
+	}
+	return &io.LimitedReader{R: r.out, N: size}, nil
+}
+
+// done skips whatever the parser left unread of blob, such as the rest of a
+// binary or skipped file, and the newline that follows each object.
+func (r *blobReader) done(blob *io.LimitedReader) error {
+	if _, err := io.Copy(io.Discard, blob); err != nil {
+		return err
+	}
+	if blob.N > 0 {
+		return io.ErrUnexpectedEOF
+	}
+	_, err := r.out.Discard(1)
+	return err
+}
+
+func (r *blobReader) close() {
+	r.in.Close()
+	_ = r.cmd.Wait()
+}
+
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanRevReadsCommittedTree(t *testing.T) {
+	dir := initGitRepo(t)
+	writeFile(t, dir, "a.go", `// @cgraph-id a
+// @cgraph-deps b
+`)
+	writeFile(t, dir, filepath.Join("sub", "b.go"), `// @cgraph-id b
+`)
+	writeFile(t, dir, filepath.Join("node_modules", "x.go"), `// @cgraph-id skipped
+`)
+	gitCommit(t, dir)
+
+	// working tree changes must not leak into the revision scan
+	writeFile(t, dir, "a.go", `// @cgraph-id changed
+`)
+
+	g, errs, err := ScanRev(context.Background(), dir, "HEAD", ScanOptions{})
+	if err != nil {
+		t.Fatalf("scan rev: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("unexpected scan errors: %+v", errs)
+	}
+	if len(g.Nodes) != 2 {
+		t.Fatalf("expected 2 nodes, got %+v", g.Nodes)
+	}
+	if n := g.Nodes["b"]; n.File != filepath.Join("sub", "b.go") || n.Line != 1 {
+		t.Fatalf("unexpected location for b: %+v", n)
+	}
+	if len(g.Edges) != 1 || g.Edges[0].From != "b" || g.Edges[0].To != "a" {
+		t.Fatalf("unexpected edges: %+v", g.Edges)
+	}
+}
+
+func TestScanRevStreamsPastPartlyReadBlobs(t *testing.T) {
+	dir := initGitRepo(t)
+	writeFile(t, dir, "a.bin", "\x00"+strings.Repeat("x", 3*binarySniffLen))
+	writeFile(t, dir, "b.go", "// @cgraph-id long\n"+strings.Repeat("y", 200)+"\n// @cgraph-id unseen\n")
+	writeFile(t, dir, "c.go", "// @cgraph-id c\n")
+	gitCommit(t, dir)
+
+	g, errs, err := ScanRev(context.Background(), dir, "HEAD", ScanOptions{MaxLineLength: 100})
+	if err != nil {
+		t.Fatalf("scan rev: %v", err)
+	}
+	if len(errs) != 1 || errs[0].Code != CodeSkippedFile || errs[0].File != "b.go" {
+		t.Fatalf("expected b.go to be skipped, got %+v", errs)
+	}
+	if len(g.Nodes) != 1 || g.Nodes["c"].Line != 1 {
+		t.Fatalf("expected only c, read after the skipped blobs, got %+v", g.Nodes)
+	}
+}
+
+func TestScanRevScopesToSubdirectory(t *testing.T) {
+	dir := initGitRepo(t)
+	writeFile(t, dir, "a.go", `// @cgraph-id a
+`)
+	writeFile(t, dir, filepath.Join("sub", "b.go"), `// @cgraph-id b
+`)
+	gitCommit(t, dir)
+
+	g, _, err := ScanRev(context.Background(), filepath.Join(dir, "sub"), "HEAD", ScanOptions{})
+	if err != nil {
+		t.Fatalf("scan rev: %v", err)
+	}
+	if len(g.Nodes) != 1 || g.Nodes["b"].File != "b.go" {
+		t.Fatalf("expected only sub/b.go relative to root, got %+v", g.Nodes)
+	}
+}
+
+func TestScanRevUnknownRevision(t *testing.T) {
+	dir := initGitRepo(t)
+	writeFile(t, dir, "a.go", "// @cgraph-id a\n")
+	gitCommit(t, dir)
+
+	_, _, err := ScanRev(context.Background(), dir, "does-not-exist", ScanOptions{})
+	if err == nil || !strings.Contains(err.Error(), "unknown revision") {
+		t.Fatalf("expected unknown revision error, got %v", err)
+	}
+}
+
+func initGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	runGitCmd(t, dir, "init", "-q")
+	return dir
+}
+
+func gitCommit(t *testing.T, dir string) {
+	t.Helper()
+	runGitCmd(t, dir, "add", "-A")
+	runGitCmd(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "test")
+}
+
+func runGitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
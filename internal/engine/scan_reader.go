@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+)
+
+// Default limits applied when ScanOptions leaves them at zero.
+const (
+	DefaultMaxFileSize   int64 = 10 << 20
+	DefaultMaxLineLength int   = 64 << 10
+)
+
+// binarySniffLen is how much of a file is checked for NUL bytes, as git does.
+const binarySniffLen = 8000
+
+var errLineTooLong = errors.New("line too long")
+
+func (o ScanOptions) maxFileSize() int64 {
+	if o.MaxFileSize == 0 {
+		return DefaultMaxFileSize
+	}
+	return o.MaxFileSize
+}
+
+func (o ScanOptions) maxLineLength() int {
+	if o.MaxLineLength == 0 {
+		return DefaultMaxLineLength
+	}
+	return o.MaxLineLength
+}
+
+// parseFSFile parses name from fsys, or its overlay contents if present.
+func parseFSFile(fsys fs.FS, name string, opts ScanOptions, syntaxes syntaxSet) (fileResult, error) {
+	rel := filepath.FromSlash(name)
+	if content, ok := opts.Overlay[name]; ok {
+		return parseReader(bytes.NewReader(content), int64(len(content)), rel, opts, syntaxes)
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return fileResult{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fileResult{}, err
+	}
+	return parseReader(f, info.Size(), rel, opts, syntaxes)
+}
+
+// parseReader streams a file of the given size through a lineParser. Binary
+// files yield nothing; files over the size or line length limits yield a
+// single warning instead of partial results.
+func parseReader(r io.Reader, size int64, rel string, opts ScanOptions, syntaxes syntaxSet) (fileResult, error) {
+	maxSize := opts.maxFileSize()
+	if maxSize > 0 && size > maxSize {
+		return skippedFile(rel, fmt.Sprintf("skipped file: %d bytes exceeds the %d byte limit", size, maxSize)), nil
+	}
+	// guard against files that grow while being read
+	limited := &io.LimitedReader{R: r, N: maxSize + 1}
+	if maxSize > 0 {
+		r = limited
+	}
+
+	// the default 4 KiB buffer would cut the sniffed prefix short
+	br := bufio.NewReaderSize(r, binarySniffLen)
+	head, err := br.Peek(binarySniffLen)
+	if err != nil && err != io.EOF {
+		return fileResult{}, err
+	}
+	if isBinary(head) {
+		return fileResult{}, nil
+	}
+
+	p := newLineParser(rel, syntaxes)
+	maxLine := opts.maxLineLength()
+	lineNo := 0
+	err = scanLines(br, maxLine, func(line string) {
+		lineNo++
+		p.feed(lineNo, line)
+	})
+	if errors.Is(err, errLineTooLong) {
+		return skippedFile(rel, fmt.Sprintf("skipped file: line %d is longer than %d bytes", lineNo+1, maxLine)), nil
+	}
+	if err != nil {
+		return fileResult{}, err
+	}
+	if maxSize > 0 && limited.N == 0 {
+		return skippedFile(rel, fmt.Sprintf("skipped file: exceeds the %d byte limit", maxSize)), nil
+	}
+	return p.finish(), nil
+}
+
+// scanLines calls fn for every line of br, without its line ending, followed
+// by a final empty line at EOF. Lines longer than maxLine bytes stop reading
+// with errLineTooLong unless maxLine is negative.
+func scanLines(br *bufio.Reader, maxLine int, fn func(string)) error {
+	var buf []byte
+	for {
+		chunk, isPrefix, err := br.ReadLine()
+		if err == io.EOF {
+			fn(string(buf))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		buf = append(buf, chunk...)
+		if maxLine >= 0 && len(buf) > maxLine {
+			return errLineTooLong
+		}
+		if isPrefix {
+			continue
+		}
+		fn(string(buf))
+		buf = buf[:0]
+	}
+}
+
+func skippedFile(rel, msg string) fileResult {
+	return fileResult{errs: []ScanError{{File: rel, Msg: msg, Rule: RuleSkippedFile, Code: CodeSkippedFile}}}
+}
+
+func isBinary(head []byte) bool {
+	// simple heuristic: treat files with NULL bytes near the start as binary
+	return bytes.IndexByte(head, 0) >= 0
+}
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestScanFSSkipsOversizedFilesWithWarning(t *testing.T) {
+	fsys := fstest.MapFS{
+		"big.sql":  {Data: []byte("-- @cgraph-id big\n" + strings.Repeat("insert into t values (1);\n", 100))},
+		"small.go": {Data: []byte("// @cgraph-id small\n")},
+	}
+
+	g, errs, err := ScanFS(context.Background(), fsys, ScanOptions{MaxFileSize: 512})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if _, ok := g.Nodes["big"]; ok {
+		t.Fatalf("expected oversized file to be skipped: %+v", g.Nodes)
+	}
+	if _, ok := g.Nodes["small"]; !ok {
+		t.Fatalf("expected small file to be scanned: %+v", g.Nodes)
+	}
+	if len(errs) != 1 || errs[0].Rule != RuleSkippedFile || errs[0].File != "big.sql" || !strings.Contains(errs[0].Msg, "byte limit") {
+		t.Fatalf("expected size warning, got %+v", errs)
+	}
+
+	g, errs, err = ScanFS(context.Background(), fsys, ScanOptions{MaxFileSize: -1})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if _, ok := g.Nodes["big"]; !ok || len(errs) != 0 {
+		t.Fatalf("expected no limit with -1, got %+v %+v", g.Nodes, errs)
+	}
+}
+
+func TestScanFSSkipsFilesWithLongLines(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bundle.min.js": {Data: []byte("// @cgraph-id bundle\n" + strings.Repeat("x", 5000) + "\n")},
+	}
+
+	g, errs, err := ScanFS(context.Background(), fsys, ScanOptions{MaxLineLength: 1024})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if len(g.Nodes) != 0 {
+		t.Fatalf("expected file with long line to be skipped: %+v", g.Nodes)
+	}
+	if len(errs) != 1 || errs[0].Rule != RuleSkippedFile || !strings.Contains(errs[0].Msg, "line 2") {
+		t.Fatalf("expected line length warning, got %+v", errs)
+	}
+}
+
+func TestParseReaderStreamsLines(t *testing.T) {
+	content := "// @cgraph-id a\r\n// @cgraph-deps b\r\n\r\n// @cgraph-id b"
+	r, err := parseReader(strings.NewReader(content), int64(len(content)), "a.go", ScanOptions{MaxLineLength: 17}, nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(r.errs) != 0 || len(r.nodes) != 2 || len(r.edges) != 1 {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+	for _, n := range r.nodes {
+		if n.ID == "b" && n.Line != 4 {
+			t.Fatalf("expected b on line 4, got %+v", n)
+		}
+	}
+}
+
+func TestParseReaderOnlySniffsHeadForBinary(t *testing.T) {
+	content := "// @cgraph-id a\n" + strings.Repeat("\n", binarySniffLen) + "\x00"
+	r, err := parseReader(strings.NewReader(content), int64(len(content)), "a.txt", ScanOptions{}, nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(r.nodes) != 1 {
+		t.Fatalf("expected NUL past the sniffed prefix to be ignored, got %+v", r)
+	}
+
+	r, err = parseReader(strings.NewReader("\x00// @cgraph-id a\n"), 17, "a.bin", ScanOptions{}, nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(r.nodes) != 0 || len(r.errs) != 0 {
+		t.Fatalf("expected binary file to be skipped, got %+v", r)
+	}
+
+	content = "// @cgraph-id a\n" + strings.Repeat("x", 5000) + "\x00\n"
+	r, err = parseReader(strings.NewReader(content), int64(len(content)), "a.dat", ScanOptions{}, nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(r.nodes) != 0 {
+		t.Fatalf("expected NUL past the first 4 KiB but within the sniffed prefix to mark the file binary, got %+v", r)
+	}
+}
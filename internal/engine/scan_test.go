@@ -1,10 +1,13 @@
 package engine
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestScanParsesNodesWithDeps(t *testing.T) {
@@ -108,6 +111,94 @@ func TestDuplicateIds(t *testing.T) {
 	}
 }
 
+func TestScanFSConcurrentReportsDuplicatesInWalkOrder(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for i := 0; i < 50; i++ {
+		fsys[fmt.Sprintf("f%02d.go", i)] = &fstest.MapFile{Data: []byte("// @cgraph-id dup\n")}
+	}
+
+	g, errs, err := ScanFS(context.Background(), fsys, ScanOptions{Concurrency: 8})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if g.Nodes["dup"].File != "f00.go" {
+		t.Fatalf("expected first file in walk order to win, got %+v", g.Nodes["dup"])
+	}
+	if len(errs) != 49 || errs[0].File != "f01.go" {
+		t.Fatalf("expected duplicates in walk order, got %+v", errs)
+	}
+}
+
+func TestScanFSIgnoreAndSyntaxes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"keep.go":         {Data: []byte("// @cgraph-id keep\n")},
+		"build/out.go":    {Data: []byte("// @cgraph-id built\n")},
+		"query.sql":       {Data: []byte("-- @cgraph-id query\n")},
+		"vendor/lib/x.go": {Data: []byte("// @cgraph-id vendored\n")},
+	}
+
+	g, _, err := ScanFS(context.Background(), fsys, ScanOptions{
+		Ignore:   []string{"build"},
+		Syntaxes: []Syntax{SyntaxSlash},
+	})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if len(g.Nodes) != 1 {
+		t.Fatalf("expected only keep, got %+v", g.Nodes)
+	}
+	if _, ok := g.Nodes["keep"]; !ok {
+		t.Fatalf("missing keep node: %+v", g.Nodes)
+	}
+}
+
+func TestScanFSSkipsCommentGraphFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"keep.go":                    {Data: []byte("// @cgraph-id keep\n")},
+		"comment-graph.yml":          {Data: []byte("# @cgraph-id stored\n")},
+		ConfigFile:                   {Data: []byte("# @cgraph-id config\n")},
+		"web/" + DefaultBaselineFile: {Data: []byte("// @cgraph-id baseline\n")},
+	}
+	g, errs, err := ScanFS(context.Background(), fsys, ScanOptions{})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if len(g.Nodes) != 1 || len(errs) != 0 {
+		t.Fatalf("expected only keep, got %+v %+v", g.Nodes, errs)
+	}
+}
+
+func TestScanFSOverlayReplacesAndAddsFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.go": {Data: []byte("// @cgraph-id a\n")},
+		"b.go": {Data: []byte("// @cgraph-id b\n")},
+	}
+	overlay := map[string][]byte{
+		"a.go":                []byte("// @cgraph-id renamed\n"),
+		"new/c.go":            []byte("// @cgraph-id c\n// @cgraph-deps b\n"),
+		"node_modules/x/d.go": []byte("// @cgraph-id skipped\n"),
+	}
+
+	g, errs, err := ScanFS(context.Background(), fsys, ScanOptions{Overlay: overlay})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("unexpected scan errors: %+v", errs)
+	}
+	for _, id := range []string{"renamed", "b", "c"} {
+		if _, ok := g.Nodes[id]; !ok {
+			t.Fatalf("missing node %s: %+v", id, g.Nodes)
+		}
+	}
+	if len(g.Nodes) != 3 {
+		t.Fatalf("expected on-disk a and skipped overlay to be absent, got %+v", g.Nodes)
+	}
+	if g.Nodes["c"].File != filepath.Join("new", "c.go") {
+		t.Fatalf("unexpected file for overlay-only node: %+v", g.Nodes["c"])
+	}
+}
+
 func TestUnknownMetadataErrors(t *testing.T) {
 	dir := t.TempDir()
 	writeFile(t, dir, "a.go", `// @cgraph-id a
@@ -121,6 +212,11 @@ func TestUnknownMetadataErrors(t *testing.T) {
 	if len(errs) != 1 || !strings.Contains(errs[0].Msg, "unknown metadata") {
 		t.Fatalf("expected unknown metadata error, got %+v", errs)
 	}
+	for _, key := range []string{"@cgraph-id", "@cgraph-deps", "@cgraph-label", "@cgraph-tags", "@cgraph-ignore"} {
+		if !strings.Contains(errs[0].Msg, key) {
+			t.Fatalf("expected %s among the suggested keys, got %q", key, errs[0].Msg)
+		}
+	}
 }
 
 func TestSpaceSeparatedDepsError(t *testing.T) {
@@ -286,3 +382,29 @@ func writeFile(t *testing.T, dir, name, content string) {
 		t.Fatalf("write file: %v", err)
 	}
 }
+
+func TestIgnoreMetadata(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.go", `// @cgraph-id a
+// @cgraph-tags public, api
+// @cgraph-ignore isolated, cycle
+// @cgraph-ignore isolated
+
+// @cgraph-id b
+// @cgraph-ignore duplicate-id
+`)
+
+	g, errs, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if got := g.Nodes["a"].Ignore; len(got) != 2 || got[0] != RuleIsolated || got[1] != RuleCycle {
+		t.Fatalf("unexpected ignores on a: %v", got)
+	}
+	if got := g.Nodes["a"].Tags; len(got) != 2 || got[1] != "api" {
+		t.Fatalf("unexpected tags on a: %v", got)
+	}
+	if len(errs) != 1 || errs[0].Line != 7 || !strings.Contains(errs[0].Msg, `"duplicate-id" cannot be suppressed`) {
+		t.Fatalf("expected error for unsupported rule, got %+v", errs)
+	}
+}
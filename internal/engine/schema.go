@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed schema/*.schema.json
+var schemaFS embed.FS
+
+// Schemas returns the names of the published JSON Schemas: "check" for
+// RenderFindingsJSON and "graph" for RenderGraphPayloadJSON.
+func Schemas() []string {
+	entries, _ := schemaFS.ReadDir("schema")
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".schema.json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Schema returns the JSON Schema with the given name.
+func Schema(name string) ([]byte, error) {
+	data, err := schemaFS.ReadFile("schema/" + name + ".schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("unknown schema %q (use %s)", name, strings.Join(Schemas(), ", "))
+	}
+	return data, nil
+}
+
+// FindingsVersion is the version of the RenderFindingsJSON output.
+const FindingsVersion = 1
+
+// RenderFindingsJSON renders only the report's findings, in the shape
+// described by the "check" schema.
+func RenderFindingsJSON(report CheckReport) ([]byte, error) {
+	findings := report.Findings
+	if findings == nil {
+		findings = []Finding{}
+	}
+	payload := map[string]any{
+		"version":  FindingsVersion,
+		"findings": findings,
+	}
+	return json.MarshalIndent(payload, "", "  ")
+}
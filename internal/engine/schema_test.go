@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// jsonFields returns the JSON property names of a struct type.
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func schemaDefs(t *testing.T, name string) map[string]struct {
+	Properties map[string]any `json:"properties"`
+} {
+	t.Helper()
+	data, err := Schema(name)
+	if err != nil {
+		t.Fatalf("schema %s: %v", name, err)
+	}
+	var doc struct {
+		Defs map[string]struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("decode schema %s: %v", name, err)
+	}
+	return doc.Defs
+}
+
+func TestSchemasMatchTypes(t *testing.T) {
+	if got := Schemas(); !slices.Equal(got, []string{"check", "graph"}) {
+		t.Fatalf("unexpected schemas %v", got)
+	}
+	types := map[string]reflect.Type{
+		"finding":       reflect.TypeOf(Finding{}),
+		"location":      reflect.TypeOf(Location{}),
+		"node":          reflect.TypeOf(graph.Node{}),
+		"edge":          reflect.TypeOf(graph.Edge{}),
+		"scanError":     reflect.TypeOf(ScanError{}),
+		"baselineEntry": reflect.TypeOf(BaselineEntry{}),
+		"report":        reflect.TypeOf(CheckReport{}),
+	}
+	for _, name := range Schemas() {
+		for def, props := range schemaDefs(t, name) {
+			typ, ok := types[def]
+			if !ok {
+				t.Fatalf("%s schema defines unknown type %s", name, def)
+			}
+			var keys []string
+			for k := range props.Properties {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+			if want := jsonFields(typ); !slices.Equal(keys, want) {
+				t.Fatalf("%s schema %s has properties %v, want %v", name, def, keys, want)
+			}
+		}
+	}
+	if _, err := Schema("nope"); err == nil {
+		t.Fatalf("expected error for unknown schema")
+	}
+}
+
+func TestRenderFindingsJSON(t *testing.T) {
+	data, err := RenderFindingsJSON(CheckReport{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(string(data), `"findings": []`) || !strings.Contains(string(data), `"version": 1`) {
+		t.Fatalf("unexpected empty output: %s", data)
+	}
+
+	g := graph.Graph{Nodes: map[string]graph.Node{"a": {ID: "a", File: "a.go", Line: 1}}}
+	data, err = RenderFindingsJSON(ValidateGraph(g, nil))
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	var out struct {
+		Findings []map[string]any `json:"findings"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(out.Findings) != 1 || out.Findings[0]["code"] != CodeIsolated || out.Findings[0]["severity"] != "error" {
+		t.Fatalf("unexpected findings: %s", data)
+	}
+}
@@ -0,0 +1,247 @@
+package engine
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+// checkCase is one rule/node pair of a test report. Rules whose findings are
+// not tied to nodes, such as invalid-metadata, get one case per finding
+// instead, named after its location, or a single passing case.
+type checkCase struct {
+	Rule     string
+	Name     string
+	Failures []Finding
+	Warnings []Finding
+}
+
+// checkCases expands the report into a case per enabled rule and node, in
+// rule order and then node ID order. Policy and limit rules count as enabled
+// only when configured.
+func checkCases(g graph.Graph, report CheckReport, cfg Config) []checkCase {
+	byRule := make(map[string][]Finding)
+	for _, f := range report.Findings {
+		byRule[f.Rule] = append(byRule[f.Rule], f)
+	}
+
+	var cases []checkCase
+	for _, r := range Rules {
+		if !ruleEnabled(r.ID, cfg) {
+			continue
+		}
+		if !nodeRule(r.ID) {
+			for _, f := range byRule[r.ID] {
+				c := checkCase{Rule: r.ID, Name: f.Message}
+				if len(f.Locations) > 0 {
+					c.Name = formatCaseLocation(f.Locations[0])
+				}
+				c.add(f)
+				cases = append(cases, c)
+			}
+			if len(byRule[r.ID]) == 0 {
+				cases = append(cases, checkCase{Rule: r.ID, Name: "all files"})
+			}
+			continue
+		}
+
+		ids := sortedNodeIDs(g)
+		index := make(map[string]int, len(ids))
+		nodeCases := make([]checkCase, len(ids))
+		for i, id := range ids {
+			index[id] = i
+			nodeCases[i] = checkCase{Rule: r.ID, Name: id}
+		}
+		for _, f := range byRule[r.ID] {
+			for _, id := range f.Nodes {
+				if i, ok := index[id]; ok {
+					nodeCases[i].add(f)
+				}
+			}
+		}
+		cases = append(cases, nodeCases...)
+	}
+	return cases
+}
+
+func ruleEnabled(rule string, cfg Config) bool {
+	if cfg.Severity(rule) == SeverityOff {
+		return false
+	}
+	switch rule {
+	case RulePolicy:
+		return len(cfg.Policies) > 0
+	case RuleMaxDepth:
+		return cfg.Limits.MaxDepth > 0
+	case RuleMaxFanIn:
+		return cfg.Limits.MaxFanIn > 0
+	case RuleMaxFanOut:
+		return cfg.Limits.MaxFanOut > 0
+	}
+	return true
+}
+
+func (c *checkCase) add(f Finding) {
+	if f.Severity == SeverityError {
+		c.Failures = append(c.Failures, f)
+	} else {
+		c.Warnings = append(c.Warnings, f)
+	}
+}
+
+// location returns where f involves this case's node, falling back to the
+// finding's primary location.
+func (c checkCase) location(f Finding) (Location, bool) {
+	for _, l := range f.Locations {
+		if l.Node == c.Name {
+			return l, true
+		}
+	}
+	if len(f.Locations) > 0 {
+		return f.Locations[0], true
+	}
+	return Location{}, false
+}
+
+// describe renders a finding with its code and location for a case body.
+func (c checkCase) describe(f Finding) string {
+	s := fmt.Sprintf("[%s] %s", f.Code, f.Message)
+	if l, ok := c.location(f); ok {
+		s = formatCaseLocation(l) + ": " + s
+	}
+	return s
+}
+
+// nodeRule reports whether the rule's findings name the nodes involved.
+func nodeRule(rule string) bool {
+	switch rule {
+	case RuleInvalidMetadata, RuleDuplicateID, RuleSkippedFile:
+		return false
+	}
+	return true
+}
+
+func formatCaseLocation(l Location) string {
+	file := filepath.ToSlash(l.File)
+	if l.Line > 0 {
+		return fmt.Sprintf("%s:%d", file, l.Line)
+	}
+	return file
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// RenderJUnit renders the report as JUnit XML with a test suite per enabled
+// rule and a test case per rule/node pair. Error findings fail their cases,
+// carrying the message and location; warnings are attached as system-out.
+func RenderJUnit(g graph.Graph, report CheckReport, cfg Config) ([]byte, error) {
+	out := junitTestSuites{Name: "comment-graph"}
+	var suite *junitTestSuite
+	for _, c := range checkCases(g, report, cfg) {
+		if suite == nil || suite.Name != c.Rule {
+			out.Suites = append(out.Suites, junitTestSuite{Name: c.Rule})
+			suite = &out.Suites[len(out.Suites)-1]
+		}
+		tc := junitTestCase{ClassName: "comment-graph." + c.Rule, Name: c.Name}
+		if n, ok := g.Nodes[c.Name]; ok && nodeRule(c.Rule) {
+			tc.File, tc.Line = filepath.ToSlash(n.File), n.Line
+		}
+		if len(c.Failures) > 0 {
+			var body []string
+			for _, f := range c.Failures {
+				body = append(body, c.describe(f))
+			}
+			tc.Failure = &junitFailure{
+				Message: c.Failures[0].Message,
+				Type:    c.Failures[0].Code,
+				Body:    strings.Join(body, "\n"),
+			}
+			suite.Failures++
+			out.Failures++
+		}
+		var warnings []string
+		for _, f := range c.Warnings {
+			warnings = append(warnings, "warning: "+c.describe(f))
+		}
+		tc.SystemOut = strings.Join(warnings, "\n")
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+		out.Tests++
+	}
+
+	data, err := xml.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// RenderTAP renders the report as TAP version 13 with a test point per
+// rule/node pair, in the same order as RenderJUnit. Failing and warned points
+// carry a YAML diagnostic block with each finding's code, severity, message
+// and location.
+func RenderTAP(g graph.Graph, report CheckReport, cfg Config) string {
+	cases := checkCases(g, report, cfg)
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(cases))
+	for i, c := range cases {
+		status := "ok"
+		if len(c.Failures) > 0 {
+			status = "not ok"
+		}
+		fmt.Fprintf(&b, "%s %d - %s: %s\n", status, i+1, c.Rule, tapEscape(c.Name))
+		findings := append(append([]Finding{}, c.Failures...), c.Warnings...)
+		if len(findings) == 0 {
+			continue
+		}
+		b.WriteString("  ---\n  findings:\n")
+		for _, f := range findings {
+			fmt.Fprintf(&b, "    - code: %s\n", f.Code)
+			fmt.Fprintf(&b, "      severity: %s\n", f.Severity)
+			fmt.Fprintf(&b, "      message: %s\n", strconv.Quote(f.Message))
+			if l, ok := c.location(f); ok {
+				fmt.Fprintf(&b, "      at: %s\n", strconv.Quote(formatCaseLocation(l)))
+			}
+		}
+		b.WriteString("  ...\n")
+	}
+	return b.String()
+}
+
+// tapEscape keeps a description from being read as a directive.
+func tapEscape(s string) string {
+	return strings.ReplaceAll(s, "#", `\#`)
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+func testReportGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a": {ID: "a", File: "a.go", Line: 1},
+			"b": {ID: "b", File: "b.go", Line: 2},
+			"c": {ID: "c", File: "c.go", Line: 3, Ignore: []string{RuleIsolated}},
+		},
+		Edges: []graph.Edge{
+			{From: "a", To: "b", Type: "blocks"},
+			{From: "b", To: "a", Type: "blocks"},
+		},
+	}
+}
+
+func TestRenderJUnitFailsRuleNodePairs(t *testing.T) {
+	g := testReportGraph()
+	errs := []ScanError{{File: "d.go", Line: 4, Msg: "metadata without @cgraph-id", Rule: RuleInvalidMetadata, Code: CodeMissingID}}
+	data, err := RenderJUnit(g, ValidateGraph(g, errs), DefaultConfig())
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	var doc struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name    string `xml:"name,attr"`
+				File    string `xml:"file,attr"`
+				Failure *struct {
+					Type string `xml:"type,attr"`
+					Body string `xml:",chardata"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("decode: %v\n%s", err, data)
+	}
+
+	failed := make(map[string]string)
+	var suites []string
+	for _, s := range doc.Suites {
+		suites = append(suites, s.Name)
+		for _, c := range s.Cases {
+			if c.Failure != nil {
+				failed[s.Name+"/"+c.Name] = c.Failure.Type + " " + c.Failure.Body
+			}
+		}
+	}
+	if strings.Contains(strings.Join(suites, ","), RulePolicy) {
+		t.Fatalf("unconfigured policy rule should not have a suite: %v", suites)
+	}
+	if doc.Failures != 3 || len(failed) != 3 {
+		t.Fatalf("expected 3 failures, got %d: %v", doc.Failures, failed)
+	}
+	if got := failed["cycle/b"]; !strings.HasPrefix(got, "CG011 b.go:2: [CG011] cycle: a -> b -> a") {
+		t.Fatalf("unexpected cycle failure for b: %q", got)
+	}
+	if got := failed["invalid-metadata/d.go:4"]; !strings.HasPrefix(got, "CG001") {
+		t.Fatalf("unexpected scan error failure: %q", got)
+	}
+}
+
+func TestRenderTAPNumbersEveryPair(t *testing.T) {
+	g := testReportGraph()
+	out := RenderTAP(g, ValidateGraph(g, nil), DefaultConfig())
+	lines := strings.Split(out, "\n")
+	if lines[0] != "TAP version 13" {
+		t.Fatalf("missing TAP header: %q", lines[0])
+	}
+	if !strings.Contains(out, "not ok 7 - cycle: a\n  ---\n  findings:\n    - code: CG011\n      severity: error\n") {
+		t.Fatalf("expected failing cycle point with diagnostics, got:\n%s", out)
+	}
+	if !strings.Contains(out, "ok 10 - isolated: a\n") || strings.Contains(out, "not ok 12 - isolated: c") {
+		t.Fatalf("expected isolated points to pass, got:\n%s", out)
+	}
+	if !strings.Contains(out, "- unused-suppression: c\n") {
+		t.Fatalf("expected unused-suppression point, got:\n%s", out)
+	}
+}
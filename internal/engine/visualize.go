@@ -2,13 +2,15 @@ package engine
 
 import (
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/kuri-sun/comment-graph/internal/graph"
 )
 
-// RenderMermaid renders the graph in Mermaid syntax.
+// RenderMermaid renders the graph's edges in Mermaid syntax. See
+// RenderMermaidWithOptions for nodes, labels, grouping and validation state.
 func RenderMermaid(g graph.Graph) string {
 	var b strings.Builder
 	b.WriteString("graph TD\n")
@@ -24,6 +26,106 @@ func RenderMermaid(g graph.Graph) string {
 	return b.String()
 }
 
+// mermaidClassDefs style the node states used by RenderMermaidWithOptions.
+var mermaidClassDefs = []string{
+	"classDef error stroke:#d33,stroke-width:2px",
+	"classDef warn stroke:#e90,stroke-width:2px",
+	"classDef isolated fill:#eee,stroke:#999",
+	"classDef undefined stroke:#d33,stroke-dasharray:4 4,color:#d33",
+}
+
+// RenderMermaidWithOptions renders every node, including isolated ones, with
+// its label as text, a subgraph per group, a class for its state (error,
+// warn, isolated or undefined) and a click link to its file and line. Edges
+// on a cycle, and edges to undefined nodes, are drawn red. Mermaid reads
+// "--" in an ID as a link and "end" as a keyword, so nodes are aliased n1,
+// n2, ... in ID order, undefined ones last.
+func RenderMermaidWithOptions(g graph.Graph, opts DiagramOptions) string {
+	cyclic, undefined := diagramIssues(g)
+	states := nodeStates(g, opts)
+	ids := append(sortedNodeIDs(g), undefined...)
+	alias := make(map[string]string, len(ids))
+	for _, id := range ids {
+		alias[id] = fmt.Sprintf("n%d", len(alias)+1)
+	}
+	for _, id := range undefined {
+		states[id] = stateUndefined
+	}
+
+	var b strings.Builder
+	b.WriteString("graph TD\n")
+	if len(g.Nodes) == 0 && len(g.Edges) == 0 {
+		b.WriteString("  %% no nodes\n")
+		return b.String()
+	}
+
+	groups, members := diagramGroups(g, opts.GroupBy)
+	for i, group := range groups {
+		indent := "  "
+		if group != "" {
+			fmt.Fprintf(&b, "  subgraph g%d[%s]\n", i, mermaidQuote(group))
+			indent = "    "
+		}
+		for _, id := range members[group] {
+			fmt.Fprintf(&b, "%s%s[%s]\n", indent, alias[id], mermaidQuote(nodeText(g.Nodes[id], opts)))
+		}
+		if group != "" {
+			b.WriteString("  end\n")
+		}
+	}
+	for _, id := range undefined {
+		fmt.Fprintf(&b, "  %s[%s]\n", alias[id], mermaidQuote(id+" (undefined)"))
+	}
+
+	var red, dashed []string
+	for i, e := range sortEdges(g.Edges) {
+		arrow := "-->"
+		if opts.EdgeTypes && e.Type != "" {
+			arrow = "-->|" + e.Type + "|"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", alias[e.From], arrow, alias[e.To])
+		switch {
+		case states[e.From] == stateUndefined || states[e.To] == stateUndefined:
+			dashed = append(dashed, fmt.Sprint(i))
+		case cyclic[e]:
+			red = append(red, fmt.Sprint(i))
+		}
+	}
+	if len(red) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:#d33\n", strings.Join(red, ","))
+	}
+	if len(dashed) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:#d33,stroke-dasharray:4 4\n", strings.Join(dashed, ","))
+	}
+
+	for _, id := range sortedNodeIDs(g) {
+		n := g.Nodes[id]
+		fmt.Fprintf(&b, "  click %s href %s %s\n", alias[id],
+			mermaidQuote(nodeLink(n, opts)), mermaidQuote(fmt.Sprintf("%s:%d", filepath.ToSlash(n.File), n.Line)))
+	}
+
+	for _, def := range mermaidClassDefs {
+		b.WriteString("  " + def + "\n")
+	}
+	byState := make(map[string][]string)
+	for _, id := range ids {
+		if state := states[id]; state != "" {
+			byState[state] = append(byState[state], alias[id])
+		}
+	}
+	for _, state := range []string{stateError, stateWarn, stateIsolated, stateUndefined} {
+		if members := byState[state]; len(members) > 0 {
+			fmt.Fprintf(&b, "  class %s %s\n", strings.Join(members, ","), state)
+		}
+	}
+	return b.String()
+}
+
+// mermaidQuote quotes node or subgraph text, escaping quotes as entities.
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
 func sortEdges(edges []graph.Edge) []graph.Edge {
 	out := append([]graph.Edge{}, edges...)
 	sort.Slice(out, func(i, j int) bool {
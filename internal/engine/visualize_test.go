@@ -36,3 +36,51 @@ func TestRenderMermaidEmptyGraph(t *testing.T) {
 		t.Fatalf("expected placeholder for empty graph, got: %s", out)
 	}
 }
+
+func TestRenderMermaidWithOptions(t *testing.T) {
+	g := diagramTestGraph()
+	g.Nodes["end"] = graph.Node{ID: "end", File: "db/c.go", Line: 9}
+	report := ValidateGraph(g, nil)
+
+	out := RenderMermaidWithOptions(g, DiagramOptions{GroupBy: GroupByDir, Labels: true, Report: &report, LinkBase: "https://example.com/blob/main/"})
+	for _, want := range []string{
+		"  subgraph g0[\"api\"]\n    n1[\"Build #quot;API#quot;\"]\n  end\n",
+		"    n4[\"end\"]\n",
+		"  n5[\"missing (undefined)\"]\n",
+		"  n1 --> n2\n  n2 --> n1\n  n2 --> n3\n  n5 --> n1\n",
+		"  linkStyle 0,1 stroke:#d33\n  linkStyle 3 stroke:#d33,stroke-dasharray:4 4\n",
+		`  click n3 href "https://example.com/blob/main/db/c.go#L4" "db/c.go:4"`,
+		"  class n1,n2,n4 error\n  class n5 undefined\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	out = RenderMermaidWithOptions(g, DiagramOptions{EdgeTypes: true})
+	if strings.Contains(out, "subgraph") || !strings.Contains(out, "n2 -->|blocks| n3") || !strings.Contains(out, "class n4 isolated") {
+		t.Fatalf("expected ungrouped output with edge types and isolated class, got:\n%s", out)
+	}
+	if out := RenderMermaidWithOptions(graph.Graph{}, DiagramOptions{}); !strings.Contains(out, "%% no nodes") {
+		t.Fatalf("expected placeholder for empty graph, got: %s", out)
+	}
+}
+
+func TestRenderMermaidAliasesIDsWithDashes(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"b":        {ID: "b", File: "b.go", Line: 1},
+			"db--init": {ID: "db--init", File: "db.go", Line: 1},
+		},
+		Edges: []graph.Edge{{From: "db--init", To: "b", Type: "blocks"}},
+	}
+	out := RenderMermaidWithOptions(g, DiagramOptions{})
+	for _, want := range []string{"  n2[\"db--init\"]\n", "  n2 --> n1\n", "  click n2 href \"db.go#L1\""} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "db--init -->") || strings.Contains(out, "n_db") {
+		t.Fatalf("expected the ID only as node text, got:\n%s", out)
+	}
+}
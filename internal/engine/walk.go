@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxSymlinkHops bounds link resolution, like the kernel's ELOOP limit.
+const maxSymlinkHops = 40
+
+var (
+	errSymlinkLoop    = errors.New("too many levels of symbolic links")
+	errOutsideScanned = errors.New("target is outside the scanned tree")
+)
+
+// listFiles returns the slash-separated paths of the files to parse, in walk
+// order, plus warnings for symlinks that could not be followed.
+func listFiles(ctx context.Context, fsys fs.FS, opts ScanOptions) ([]string, []ScanError, error) {
+	w := &walker{
+		ctx:   ctx,
+		fsys:  fsys,
+		opts:  opts,
+		dirs:  make(map[string]bool),
+		seen:  make(map[string]bool),
+		stack: make(map[string]bool),
+	}
+	if err := w.walk(".", "."); err != nil {
+		return nil, nil, err
+	}
+	return addOverlayFiles(w.files, opts), w.warnings, nil
+}
+
+// walker visits directories in lexical order. Each directory is tracked by the
+// path it was reached through (name) and its symlink-free location (real), so
+// followed links can be checked for loops and files are scanned only once.
+// Links to targets the walk reaches without links are skipped, so files are
+// reported under their real path whenever they have one.
+type walker struct {
+	ctx      context.Context
+	fsys     fs.FS
+	opts     ScanOptions
+	files    []string
+	warnings []ScanError
+	dirs     map[string]bool // real directories already walked
+	seen     map[string]bool // real files already listed
+	stack    map[string]bool // real directories being walked
+}
+
+func (w *walker) walk(name, real string) error {
+	w.dirs[real] = true
+	w.stack[real] = true
+	defer delete(w.stack, real)
+
+	entries, err := fs.ReadDir(w.fsys, name)
+	if err != nil {
+		return err
+	}
+	for _, d := range entries {
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+		childName := path.Join(name, d.Name())
+		childReal := path.Join(real, d.Name())
+		isDir := d.IsDir()
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			if !w.opts.FollowSymlinks {
+				continue
+			}
+			target, info, err := w.resolve(childReal)
+			if err != nil {
+				w.warn(childName, fmt.Sprintf("skipped symlink: %v", err))
+				continue
+			}
+			childReal = target
+			isDir = info.IsDir()
+			if isDir && w.stack[childReal] {
+				w.warn(childName, fmt.Sprintf("skipped symlink: loop back to %s", childReal))
+				continue
+			}
+			if w.direct(childReal, isDir) {
+				continue
+			}
+		}
+
+		if isDir {
+			if shouldSkipDir(d.Name()) || w.opts.ignored(childName) || w.dirs[childReal] {
+				continue
+			}
+			if err := w.walk(childName, childReal); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if isGraphFile(d.Name()) || w.opts.ignored(childName) || w.seen[childReal] {
+			continue
+		}
+		w.seen[childReal] = true
+		w.files = append(w.files, childName)
+	}
+	return nil
+}
+
+// direct reports whether the walk reaches the symlink-free path real without
+// following links: no directory on the way is skipped or ignored, and
+// neither is the target itself.
+func (w *walker) direct(real string, isDir bool) bool {
+	if real == "." {
+		return true
+	}
+	parts := strings.Split(real, "/")
+	for i, part := range parts {
+		prefix := strings.Join(parts[:i+1], "/")
+		if w.opts.ignored(prefix) {
+			return false
+		}
+		if i < len(parts)-1 || isDir {
+			if shouldSkipDir(part) {
+				return false
+			}
+		} else if isGraphFile(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve follows every symlink in the slash-separated name and returns the
+// resulting path within fsys along with the target's file info.
+func (w *walker) resolve(name string) (string, fs.FileInfo, error) {
+	resolved := "."
+	rest := strings.Split(name, "/")
+	hops := 0
+	for len(rest) > 0 {
+		part := rest[0]
+		rest = rest[1:]
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if resolved == "." {
+				return "", nil, errOutsideScanned
+			}
+			resolved = path.Dir(resolved)
+			continue
+		}
+
+		next := path.Join(resolved, part)
+		info, err := fs.Lstat(w.fsys, next)
+		if err != nil {
+			return "", nil, err
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		hops++
+		if hops > maxSymlinkHops {
+			return "", nil, errSymlinkLoop
+		}
+		target, err := fs.ReadLink(w.fsys, next)
+		if err != nil {
+			return "", nil, err
+		}
+		target = filepath.ToSlash(target)
+		if path.IsAbs(target) || filepath.IsAbs(target) {
+			return "", nil, errOutsideScanned
+		}
+		// relative targets are resolved from the link's directory
+		rest = append(strings.Split(target, "/"), rest...)
+	}
+
+	info, err := fs.Stat(w.fsys, resolved)
+	if err != nil {
+		return "", nil, err
+	}
+	return resolved, info, nil
+}
+
+func (w *walker) warn(name, msg string) {
+	w.warnings = append(w.warnings, ScanError{File: filepath.FromSlash(name), Msg: msg, Rule: RuleSkippedFile, Code: CodeSkippedSymlink})
+}
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func symlinkFS() fstest.MapFS {
+	link := func(target string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(target), Mode: fs.ModeSymlink}
+	}
+	return fstest.MapFS{
+		"shared/x.go":     {Data: []byte("// @cgraph-id shared\n")},
+		"shared/self":     link("."),
+		"apps/web/shared": link("../../shared"),
+		"apps/web/zalias": link("../web/shared/x.go"),
+		"apps/out":        link("../../elsewhere"),
+		"apps/broken":     link("missing.go"),
+	}
+}
+
+func TestScanFSSkipsSymlinksByDefault(t *testing.T) {
+	g, errs, err := ScanFS(context.Background(), symlinkFS(), ScanOptions{})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("unexpected scan errors: %+v", errs)
+	}
+	if g.Nodes["shared"].File != filepath.Join("shared", "x.go") {
+		t.Fatalf("expected node at its real path, got %+v", g.Nodes)
+	}
+}
+
+func TestScanFSFollowSymlinks(t *testing.T) {
+	g, errs, err := ScanFS(context.Background(), symlinkFS(), ScanOptions{FollowSymlinks: true})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if len(g.Nodes) != 1 {
+		t.Fatalf("expected the shared file to be scanned once, got %+v", g.Nodes)
+	}
+	if got := g.Nodes["shared"].File; got != filepath.Join("shared", "x.go") {
+		t.Fatalf("expected node reported under its real path, got %q", got)
+	}
+
+	want := map[string]string{
+		filepath.Join("apps", "broken"): "skipped symlink",
+		filepath.Join("apps", "out"):    "outside the scanned tree",
+		filepath.Join("shared", "self"): "loop",
+	}
+	for _, e := range errs {
+		if e.Rule != RuleSkippedFile {
+			t.Fatalf("expected only warnings, got %+v", e)
+		}
+		if msg, ok := want[e.File]; ok && strings.Contains(e.Msg, msg) {
+			delete(want, e.File)
+		}
+	}
+	if len(want) != 0 {
+		t.Fatalf("missing warnings %v in %+v", want, errs)
+	}
+
+	report := ValidateGraph(g, errs)
+	if len(report.ScanErrors) != 0 || len(report.ScanWarnings) != len(errs) {
+		t.Fatalf("expected warnings to be split from errors: %+v", report)
+	}
+}
+
+func TestScanFollowsOSSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, filepath.Join("packages", "shared", "index.ts"), "// @cgraph-id shared\n")
+	writeFile(t, dir, filepath.Join("apps", "web", "index.ts"), "// @cgraph-id web\n// @cgraph-deps shared\n")
+	if err := os.Symlink(filepath.Join("..", "..", "packages", "shared"), filepath.Join(dir, "apps", "web", "shared")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink("web", filepath.Join(dir, "apps", "loop")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	g, errs, err := ScanFS(context.Background(), os.DirFS(dir), ScanOptions{FollowSymlinks: true})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("unexpected scan errors: %+v", errs)
+	}
+	if len(g.Nodes) != 2 {
+		t.Fatalf("expected both nodes once, got %+v", g.Nodes)
+	}
+	if got := g.Nodes["web"].File; got != filepath.Join("apps", "web", "index.ts") {
+		t.Fatalf("expected web under its real path rather than the earlier link, got %q", got)
+	}
+}
+
+func TestScanFSFollowsLinksIntoSkippedTargets(t *testing.T) {
+	fsys := fstest.MapFS{
+		"vendor/lib/x.go":  {Data: []byte("// @cgraph-id lib\n")},
+		"generated/y.go":   {Data: []byte("// @cgraph-id gen\n")},
+		"app/lib":          {Data: []byte("../vendor/lib"), Mode: fs.ModeSymlink},
+		"app/generated.go": {Data: []byte("../generated/y.go"), Mode: fs.ModeSymlink},
+	}
+	g, errs, err := ScanFS(context.Background(), fsys, ScanOptions{FollowSymlinks: true, Ignore: []string{"generated"}})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("unexpected scan errors: %+v", errs)
+	}
+	if got := g.Nodes["lib"].File; got != filepath.Join("app", "lib", "x.go") {
+		t.Fatalf("expected vendored node reached through the link, got %q", got)
+	}
+	if got := g.Nodes["gen"].File; got != filepath.Join("app", "generated.go") {
+		t.Fatalf("expected ignored node reached through the link, got %q", got)
+	}
+}
@@ -64,6 +64,12 @@ func writeNodes(b *strings.Builder, nodes map[string]graph.Node) {
 		if n.Label != "" {
 			b.WriteString("    label: " + yamlQuote(n.Label) + "\n")
 		}
+		if len(n.Tags) > 0 {
+			b.WriteString("    tags: [" + strings.Join(n.Tags, ", ") + "]\n")
+		}
+		if len(n.Ignore) > 0 {
+			b.WriteString("    ignore: [" + strings.Join(n.Ignore, ", ") + "]\n")
+		}
 		b.WriteString("\n")
 	}
 }
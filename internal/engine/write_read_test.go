@@ -15,7 +15,7 @@ func TestWriteReadGraphRoundTrip(t *testing.T) {
 	g := graph.Graph{
 		Nodes: map[string]graph.Node{
 			"a": {ID: "a", File: "a.go", Line: 1},
-			"b": {ID: "b", File: "b.go", Line: 2},
+			"b": {ID: "b", File: "b.go", Line: 2, Tags: []string{"public"}, Ignore: []string{RuleCycle, RuleIsolated}},
 		},
 		Edges: []graph.Edge{
 			{From: "a", To: "b", Type: "blocks"},
@@ -36,6 +36,12 @@ func TestWriteReadGraphRoundTrip(t *testing.T) {
 	if !GraphsEqual(g, read) {
 		t.Fatalf("graphs not equal after round trip: %+v vs %+v", g, read)
 	}
+	if got := read.Nodes["b"].Ignore; len(got) != 2 || got[0] != RuleCycle || got[1] != RuleIsolated {
+		t.Fatalf("ignore list not preserved: %v", got)
+	}
+	if got := read.Nodes["b"].Tags; len(got) != 1 || got[0] != "public" {
+		t.Fatalf("tags not preserved: %v", got)
+	}
 }
 
 func TestWriteGraphEmptyFormatsSections(t *testing.T) {
@@ -97,3 +103,30 @@ func readFile(t *testing.T, path string) string {
 	}
 	return string(data)
 }
+
+func TestReadGraphFileAcceptsGraphPayloadJSON(t *testing.T) {
+	dir := t.TempDir()
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"a": {ID: "a", File: "a.go", Line: 1, Label: "A"},
+			"b": {ID: "b", File: "b.go", Line: 2},
+		},
+		Edges: []graph.Edge{{From: "a", To: "b", Type: "blocks"}},
+	}
+	payload, err := RenderGraphPayloadJSON(g, nil, false)
+	if err != nil {
+		t.Fatalf("render payload: %v", err)
+	}
+	path := filepath.Join(dir, "cache.json")
+	if err := os.WriteFile(path, payload, 0o644); err != nil {
+		t.Fatalf("write payload: %v", err)
+	}
+
+	read, err := ReadGraphFile(path)
+	if err != nil {
+		t.Fatalf("read graph file: %v", err)
+	}
+	if !GraphsEqual(g, read) || read.Nodes["a"].Label != "A" {
+		t.Fatalf("graphs not equal after json round trip: %+v vs %+v", g, read)
+	}
+}
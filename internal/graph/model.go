@@ -6,6 +6,10 @@ type Node struct {
 	File  string
 	Line  int
 	Label string
+	// Tags are free-form labels from @cgraph-tags, used by policies.
+	Tags []string `json:",omitempty"`
+	// Ignore lists the rules suppressed for this node with @cgraph-ignore.
+	Ignore []string `json:",omitempty"`
 }
 
 // Edge models a dependency edge between nodes.
@@ -0,0 +1,154 @@
+// Package commentgraph exposes the comment-graph scanner and validator as a Go
+// library, so the graph can be built from any fs.FS (an OS directory, an
+// embed.FS, or an in-memory fstest.MapFS) without going through the CLI.
+package commentgraph
+
+import (
+	"context"
+	"io/fs"
+	"os"
+
+	"github.com/kuri-sun/comment-graph/internal/engine"
+	"github.com/kuri-sun/comment-graph/internal/graph"
+)
+
+type (
+	// Graph is a scanned comment graph keyed by node ID.
+	Graph = graph.Graph
+	// Node is a comment-graph node discovered in source code.
+	Node = graph.Node
+	// Edge is a dependency between two nodes.
+	Edge = graph.Edge
+	// ScanError describes a metadata problem found while scanning.
+	ScanError = engine.ScanError
+	// CheckReport holds the results of Validate.
+	CheckReport = engine.CheckReport
+	// Syntax names a comment style recognised by the scanner.
+	Syntax = engine.Syntax
+	// Config holds rule severities read from .comment-graph.yml.
+	Config = engine.Config
+	// Finding is a single rule violation reported by Validate.
+	Finding = engine.Finding
+	// Severity is error, warn or off.
+	Severity = engine.Severity
+	// Code documents a stable error code such as CG001.
+	Code = engine.Code
+)
+
+// Supported comment syntaxes.
+const (
+	SyntaxSlash     = engine.SyntaxSlash
+	SyntaxHash      = engine.SyntaxHash
+	SyntaxDash      = engine.SyntaxDash
+	SyntaxBlock     = engine.SyntaxBlock
+	SyntaxHTML      = engine.SyntaxHTML
+	SyntaxDocstring = engine.SyntaxDocstring
+)
+
+// Options configure a scan. The zero value scans every file with all comment
+// syntaxes, one file at a time.
+type Options = engine.ScanOptions
+
+// Option adjusts Options.
+type Option func(*Options)
+
+// WithIgnore skips files and directories matching any of the slash-separated
+// glob patterns. Patterns containing "/" match the path from the scan root and
+// may use "**"; other patterns match a base name at any depth.
+func WithIgnore(patterns ...string) Option {
+	return func(o *Options) {
+		o.Ignore = append(o.Ignore, patterns...)
+	}
+}
+
+// WithSyntaxes restricts metadata to the given comment syntaxes.
+func WithSyntaxes(syntaxes ...Syntax) Option {
+	return func(o *Options) {
+		o.Syntaxes = append(o.Syntaxes, syntaxes...)
+	}
+}
+
+// WithConcurrency parses up to n files in parallel.
+func WithConcurrency(n int) Option {
+	return func(o *Options) {
+		o.Concurrency = n
+	}
+}
+
+// WithFollowSymlinks scans symlinked files and directories instead of
+// skipping them. fsys must implement fs.ReadLinkFS (os.DirFS does); loops and
+// links leaving fsys are skipped and reported as warnings.
+func WithFollowSymlinks() Option {
+	return func(o *Options) {
+		o.FollowSymlinks = true
+	}
+}
+
+// WithMaxFileSize skips files larger than n bytes with a warning. A negative n
+// disables the limit; the default is 10 MiB.
+func WithMaxFileSize(n int64) Option {
+	return func(o *Options) {
+		o.MaxFileSize = n
+	}
+}
+
+// WithMaxLineLength skips files containing lines longer than n bytes, such as
+// minified bundles, with a warning. A negative n disables the limit; the
+// default is 64 KiB.
+func WithMaxLineLength(n int) Option {
+	return func(o *Options) {
+		o.MaxLineLength = n
+	}
+}
+
+// WithOverlay scans the given contents instead of what is in fsys. Keys are
+// slash-separated paths relative to the scan root; paths missing from fsys
+// are scanned as new files.
+func WithOverlay(files map[string][]byte) Option {
+	return func(o *Options) {
+		if o.Overlay == nil {
+			o.Overlay = make(map[string][]byte, len(files))
+		}
+		for name, content := range files {
+			o.Overlay[name] = content
+		}
+	}
+}
+
+// ScanFS builds the comment graph for every file in fsys. Metadata problems
+// are returned as ScanErrors; the error result is reserved for I/O failures
+// and cancellation of ctx.
+func ScanFS(ctx context.Context, fsys fs.FS, opts ...Option) (Graph, []ScanError, error) {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return engine.ScanFS(ctx, fsys, o)
+}
+
+// ScanDir is ScanFS over the OS directory root.
+func ScanDir(ctx context.Context, root string, opts ...Option) (Graph, []ScanError, error) {
+	return ScanFS(ctx, os.DirFS(root), opts...)
+}
+
+// Validate checks g for undefined references, cycles and isolated nodes and
+// folds in the scan errors from ScanFS.
+func Validate(g Graph, scanErrs []ScanError) CheckReport {
+	return engine.ValidateGraph(g, scanErrs)
+}
+
+// LoadConfig reads .comment-graph.yml from root, or returns the default
+// configuration if there is none.
+func LoadConfig(root string) (Config, error) {
+	return engine.LoadConfig(root)
+}
+
+// LookupCode returns the documentation for an error code.
+func LookupCode(id string) (Code, bool) {
+	return engine.LookupCode(id)
+}
+
+// ValidateWithConfig is Validate with rule severities taken from cfg.
+func ValidateWithConfig(g Graph, scanErrs []ScanError, cfg Config) CheckReport {
+	return engine.ValidateGraphWithConfig(g, scanErrs, cfg)
+}
@@ -0,0 +1,51 @@
+package commentgraph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestScanFSInMemory(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.go":           {Data: []byte("// @cgraph-id a\n// @cgraph-deps b\n")},
+		"lib/b.py":       {Data: []byte("# @cgraph-id b\n")},
+		"gen/skip.go":    {Data: []byte("// @cgraph-id generated\n")},
+		"docs/notes.sql": {Data: []byte("-- @cgraph-id notes\n")},
+	}
+
+	g, errs, err := ScanFS(context.Background(), fsys,
+		WithIgnore("gen/**"),
+		WithSyntaxes(SyntaxSlash, SyntaxHash),
+		WithConcurrency(4),
+	)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("unexpected scan errors: %+v", errs)
+	}
+	if len(g.Nodes) != 2 {
+		t.Fatalf("expected nodes a and b only, got %+v", g.Nodes)
+	}
+	if len(g.Edges) != 1 || g.Edges[0].From != "b" || g.Edges[0].To != "a" {
+		t.Fatalf("unexpected edges: %+v", g.Edges)
+	}
+
+	report := Validate(g, errs)
+	if len(report.UndefinedEdges) != 0 || len(report.Cycles) != 0 || len(report.Isolated) != 0 {
+		t.Fatalf("unexpected findings: %+v", report)
+	}
+}
+
+func TestScanFSCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fsys := fstest.MapFS{"a.go": {Data: []byte("// @cgraph-id a\n")}}
+	_, _, err := ScanFS(ctx, fsys)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}